@@ -0,0 +1,546 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/bazel_blog/internal/config"
+	"github.com/yourusername/bazel_blog/internal/generator"
+	"github.com/yourusername/bazel_blog/internal/ui"
+)
+
+// The subcommands in this file do what the interactive menus do without a
+// terminal, so scripts, CI jobs and editor plugins can drive bazel. Run
+// without a subcommand, post, page, theme, font and config open their menu.
+
+// confirm asks a yes/no question on stdin; anything but y or yes is a no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	fmt.Println()
+	return false
+}
+
+// printJSON writes v as indented JSON
+func printJSON(v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// contentCommands are the generator functions behind `bazel post` or `bazel page`
+type contentCommands struct {
+	kind   string                   // "post" or "page"
+	new    func(title string) error // Creates and opens in the editor
+	create func(title string) (string, error)
+	edit   func(name string) error
+	delete func(name string) error
+	list   func(drafts bool) ([]generator.ContentInfo, error)
+	names  func() ([]string, error) // Published names, for completion
+	drafts func() ([]string, error) // Draft names, for completion
+	menu   func()                   // Interactive menu
+
+	// resolve turns a name, or another reference such as a slug, into the
+	// name of existing content. Names are used as given when it is nil.
+	resolve func(ref string) (string, error)
+}
+
+var postCommands = contentCommands{
+	kind:   "post",
+	new:    generator.NewPost,
+	create: generator.CreatePost,
+	edit:   generator.EditPost,
+	delete: generator.DeletePost,
+	list:   generator.ListPostInfo,
+	names:  generator.ListPosts,
+	drafts: generator.ListDraftPosts,
+	menu:   ui.RunPostMenu,
+
+	resolve: generator.ResolvePost,
+}
+
+var pageCommands = contentCommands{
+	kind:   "page",
+	new:    generator.NewPage,
+	create: generator.CreatePage,
+	edit:   generator.EditPage,
+	delete: generator.DeletePage,
+	list:   generator.ListPageInfo,
+	names:  generator.ListPages,
+	drafts: generator.ListDraftPages,
+	menu:   ui.RunPageMenu,
+}
+
+// argument is the usage of the argument naming existing content
+func (c contentCommands) argument() string {
+	if c.resolve != nil {
+		return "<name|slug|url>"
+	}
+	return "<name>"
+}
+
+// resolveName returns the name of the content ref refers to
+func (c contentCommands) resolveName(ref string) (string, error) {
+	if c.resolve == nil {
+		return ref, nil
+	}
+	return c.resolve(ref)
+}
+
+// newContentCommand returns `bazel post` or `bazel page`
+func newContentCommand(c contentCommands) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   c.kind,
+		Short: fmt.Sprintf("Manage %ss, or open the %s menu without a subcommand", c.kind, c.kind),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSite(func() error {
+				c.menu()
+				return nil
+			})
+		},
+	}
+
+	var noEdit bool
+	newCmd := &cobra.Command{
+		Use:   "new <title>",
+		Short: fmt.Sprintf("Create a %s and open it in $EDITOR", c.kind),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inSite(func() error {
+				if !noEdit {
+					return c.new(args[0])
+				}
+				filename, err := c.create(args[0])
+				if err != nil {
+					return err
+				}
+				statusf("Created %s: %s\n", c.kind, filename)
+				return nil
+			})
+		},
+	}
+	newCmd.Flags().BoolVar(&noEdit, "no-edit", false, "Only create the file, without opening an editor")
+
+	var drafts, asJSON bool
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: fmt.Sprintf("List %ss with their titles", c.kind),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inSite(func() error {
+				infos, err := c.list(drafts)
+				if err != nil {
+					return err
+				}
+				if asJSON {
+					return printJSON(infos)
+				}
+				for _, info := range infos {
+					fmt.Printf("%s\t%s\n", info.Name, info.Title)
+				}
+				return nil
+			})
+		},
+	}
+	listCmd.Flags().BoolVar(&drafts, "drafts", false, "List drafts instead")
+	listCmd.Flags().BoolVar(&asJSON, "json", false, "Print name, title, date, file and draft as JSON")
+
+	editCmd := &cobra.Command{
+		Use:               "edit " + c.argument(),
+		Short:             fmt.Sprintf("Open a %s in $EDITOR", c.kind),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeContent(c, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inSite(func() error {
+				name, err := c.resolveName(args[0])
+				if err != nil {
+					return err
+				}
+				return c.edit(name)
+			})
+		},
+	}
+
+	var yes bool
+	deleteCmd := &cobra.Command{
+		Use:               "delete " + c.argument(),
+		Short:             fmt.Sprintf("Delete a %s after asking for confirmation", c.kind),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeContent(c, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inSite(func() error {
+				name, err := c.resolveName(args[0])
+				if err != nil {
+					return err
+				}
+				if !yes && !confirm(fmt.Sprintf("Delete %s %s? This cannot be undone.", c.kind, name)) {
+					return fmt.Errorf("%s not deleted, pass --yes to delete without asking", c.kind)
+				}
+				if err := c.delete(name); err != nil {
+					return err
+				}
+				statusf("Deleted %s: %s\n", c.kind, name)
+				return nil
+			})
+		},
+	}
+	deleteCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking")
+
+	cmd.AddCommand(newCmd, listCmd, editCmd, deleteCmd)
+	for _, publish := range []bool{true, false} {
+		cmd.AddCommand(newContentPublishCommand(c, publish))
+	}
+
+	if c.kind == "page" {
+		cmd.AddCommand(&cobra.Command{
+			Use:   "order [name...]",
+			Short: "Print the navigation order, or move the named pages and sections to the front",
+			ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				if applyGlobalOptions() != nil {
+					return nil, cobra.ShellCompDirectiveError
+				}
+				entries, err := generator.ListNavEntries()
+				if err != nil {
+					return nil, cobra.ShellCompDirectiveError
+				}
+				var names []string
+				for _, entry := range entries {
+					names = append(names, entry.Name)
+				}
+				return names, cobra.ShellCompDirectiveNoFileComp
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return inSite(func() error {
+					return runPageOrder(args)
+				})
+			},
+		})
+	}
+	return cmd
+}
+
+// newContentPublishCommand returns `bazel post publish` or `bazel post unpublish`
+func newContentPublishCommand(c contentCommands, publish bool) *cobra.Command {
+	use, short := "publish", "Publish a draft "+c.kind
+	if !publish {
+		use, short = "unpublish", fmt.Sprintf("Move a %s back to drafts", c.kind)
+	}
+	return &cobra.Command{
+		Use:               use + " " + c.argument(),
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeContent(c, publish),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inSite(func() error {
+				name, err := c.resolveName(args[0])
+				if err != nil {
+					return err
+				}
+				if err := setPublished(c.kind, name, publish); err != nil {
+					return err
+				}
+				if publish {
+					statusf("Published %s: %s\n", c.kind, name)
+				} else {
+					statusf("Moved %s to drafts: %s\n", c.kind, name)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// completeContent completes the name of a post or page, or of a draft when
+// drafts is set
+func completeContent(c contentCommands, drafts bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 || applyGlobalOptions() != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		list := c.names
+		if drafts {
+			list = c.drafts
+		}
+		names, err := list()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// runPageOrder prints the navigation order of the pages, or moves the named
+// pages and sections to the front in the given order and saves it
+func runPageOrder(names []string) error {
+	entries, err := generator.ListNavEntries()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		for _, entry := range entries {
+			fmt.Printf("%s\t%s\n", entry.Name, entry.Title)
+		}
+		return nil
+	}
+
+	byName := make(map[string]generator.NavEntry, len(entries))
+	for _, entry := range entries {
+		byName[entry.Name] = entry
+	}
+	ordered := make([]generator.NavEntry, 0, len(entries))
+	moved := make(map[string]bool, len(names))
+	for _, name := range names {
+		entry, ok := byName[strings.TrimSuffix(name, "/")]
+		if !ok {
+			return fmt.Errorf("no page or section named %s in the navigation", name)
+		}
+		if !moved[entry.Name] {
+			ordered = append(ordered, entry)
+			moved[entry.Name] = true
+		}
+	}
+	for _, entry := range entries {
+		if !moved[entry.Name] {
+			ordered = append(ordered, entry)
+		}
+	}
+
+	if err := generator.SaveNavOrder(ordered); err != nil {
+		return err
+	}
+	statusf("Page order saved\n")
+	return nil
+}
+
+// choiceCommand is `bazel theme` or `bazel font`, which set a config key to
+// one of a fixed list of values
+type choiceCommand struct {
+	kind    string // "theme" or "font"
+	label   string // Capitalised kind for messages
+	key     string // Config key the choice is stored in
+	choices []string
+	rebuild bool // Rebuild the site after a change, as the theme menu does
+}
+
+var themeCommand = choiceCommand{
+	kind:    "theme",
+	label:   "Theme",
+	key:     "theme.color_scheme",
+	choices: config.ColorSchemes,
+	rebuild: true,
+}
+
+var fontCommand = choiceCommand{
+	kind:    "font",
+	label:   "Font",
+	key:     "theme.font",
+	choices: config.Fonts,
+}
+
+// newChoiceCommand returns `bazel theme` or `bazel font`
+func newChoiceCommand(c choiceCommand) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   c.kind,
+		Short: fmt.Sprintf("List or set the %s, or open the %s menu without a subcommand", c.kind, c.kind),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSite(func() error {
+				if c.kind == "theme" {
+					ui.RunThemeMenu()
+				} else {
+					ui.RunFontMenu()
+				}
+				return nil
+			})
+		},
+	}
+
+	var asJSON bool
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: fmt.Sprintf("List the %ss, marking the current one with *", c.kind),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if asJSON {
+				return printJSON(c.choices)
+			}
+			return inSite(func() error {
+				cfg, err := config.LoadConfig()
+				if err != nil {
+					return err
+				}
+				current, _ := cfg.Get(c.key)
+				for _, choice := range c.choices {
+					marker := " "
+					if choice == current {
+						marker = "*"
+					}
+					fmt.Printf("%s %s\n", marker, choice)
+				}
+				return nil
+			})
+		},
+	}
+	listCmd.Flags().BoolVar(&asJSON, "json", false, "Print the choices as a JSON array")
+
+	setCmd := &cobra.Command{
+		Use:       "set <name>",
+		Short:     fmt.Sprintf("Set the %s", c.kind),
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: c.choices,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inSite(func() error {
+				cfg, err := config.LoadBaseConfig()
+				if err != nil {
+					return err
+				}
+				if err := cfg.Set(c.key, args[0]); err != nil {
+					return err
+				}
+				if err := cfg.Save(); err != nil {
+					return err
+				}
+				statusf("%s set to %s\n", c.label, args[0])
+				if c.rebuild {
+					// Warnings go to stderr so they do not mix with the output
+					if err := generator.BuildSite(); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: Failed to rebuild site: %v\n", err)
+						fmt.Fprintf(os.Stderr, "Run 'bazel build' manually to apply %s changes.\n", c.kind)
+					}
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.AddCommand(listCmd, setCmd)
+	return cmd
+}
+
+// newConfigCommand returns `bazel config`
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read or change settings, or open the config menu without a subcommand",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSite(func() error {
+				ui.RunConfigMenu()
+				return nil
+			})
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:               "get <key>",
+		Short:             "Print a setting by its dotted key, such as theme.font",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inSite(func() error {
+				cfg, err := config.LoadConfig()
+				if err != nil {
+					return err
+				}
+				value, err := cfg.Get(args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Println(value)
+				return nil
+			})
+		},
+	})
+
+	var resolved bool
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the config file, or with --resolved every effective setting and its source",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inSite(func() error {
+				if resolved {
+					return printResolvedConfig()
+				}
+				cfg, err := config.LoadBaseConfig()
+				if err != nil {
+					return err
+				}
+				return toml.NewEncoder(os.Stdout).Encode(cfg)
+			})
+		},
+	}
+	showCmd.Flags().BoolVar(&resolved, "resolved", false, "Include --env, BAZEL_ environment variables and --set")
+	cmd.AddCommand(showCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:               "set <key> <value>",
+		Short:             "Change a setting by its dotted key and save the config",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inSite(func() error {
+				cfg, err := config.LoadBaseConfig()
+				if err != nil {
+					return err
+				}
+				if err := cfg.Set(args[0], args[1]); err != nil {
+					return err
+				}
+				if err := cfg.Save(); err != nil {
+					return err
+				}
+				statusf("Set %s to %q\n", args[0], args[1])
+				return nil
+			})
+		},
+	})
+	return cmd
+}
+
+// printResolvedConfig prints every setting of the merged config with the
+// file, environment variable or flag its value came from
+func printResolvedConfig() error {
+	cfg, sources, err := config.LoadResolvedConfig()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, key := range cfg.Keys() {
+		value, _ := cfg.Get(key)
+		// Tables such as menu are printed on one line
+		value = strings.Join(strings.Fields(value), " ")
+		if value == "" {
+			value = `""`
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", key, sources[key], value)
+	}
+	return w.Flush()
+}
+
+// completeConfigKeys completes the key of `bazel config get` and `set`
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || applyGlobalOptions() != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return cfg.Keys(), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/bazel_blog/internal/config"
+	"github.com/yourusername/bazel_blog/internal/generator"
+)
+
+// newTestSite moves into a new site directory holding files
+func newTestSite(t *testing.T, files map[string]string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	if _, ok := files["bazel.toml"]; !ok {
+		files["bazel.toml"] = "title = \"CLI\"\n"
+	}
+	for name, content := range files {
+		path := filepath.FromSlash(name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// captureStdout returns what fn prints to stdout along with its error
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	err = fn()
+	w.Close()
+	return <-out, err
+}
+
+// runBazel runs the bazel command tree with args and returns its output
+func runBazel(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir()) // Keep the site registry out of the way
+	configPath, env, overrides := config.Path, config.Env, config.Overrides
+	t.Cleanup(func() { config.Path, config.Env, config.Overrides = configPath, env, overrides })
+
+	return captureStdout(t, func() error {
+		root := newRootCommand()
+		root.SetArgs(args)
+		return root.Execute()
+	})
+}
+
+func runPost(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	return runBazel(t, append([]string{"post"}, args...)...)
+}
+
+func TestPostCommands(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/hello.md":        "---\ntitle: Hello\ndate: 2024-01-02\n---\n\nHello\n",
+		"posts/drafts/later.md": "---\ntitle: Later\n---\n\nLater\n",
+	})
+
+	out, err := runPost(t, "new", "My Post", "--no-edit")
+	if err != nil || out != "Created post: posts/My_Post.md\n" {
+		t.Fatalf("post new = %q, %v", out, err)
+	}
+
+	out, err = runPost(t, "list", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var infos []generator.ContentInfo
+	if err := json.Unmarshal([]byte(out), &infos); err != nil {
+		t.Fatalf("post list --json printed %q: %v", out, err)
+	}
+	if len(infos) != 2 || infos[0].Name != "My_Post" || infos[0].Title != "My Post" ||
+		infos[1].Name != "hello" || infos[1].Date != "2024-01-02" {
+		t.Errorf("post list --json = %+v", infos)
+	}
+
+	out, err = runPost(t, "list", "--drafts")
+	if err != nil || out != "later\tLater\n" {
+		t.Errorf("post list --drafts = %q, %v", out, err)
+	}
+
+	if out, err = runPost(t, "publish", "later"); err != nil || out != "Published post: later\n" {
+		t.Errorf("post publish = %q, %v", out, err)
+	}
+	if _, err := os.Stat(filepath.Join("posts", "later.md")); err != nil {
+		t.Errorf("published post was not moved: %v", err)
+	}
+	if out, err = runPost(t, "unpublish", "hello"); err != nil || out != "Moved post to drafts: hello\n" {
+		t.Errorf("post unpublish = %q, %v", out, err)
+	}
+
+	if out, err = runPost(t, "delete", "My_Post", "--yes"); err != nil || out != "Deleted post: My_Post\n" {
+		t.Errorf("post delete = %q, %v", out, err)
+	}
+	if _, err := os.Stat(filepath.Join("posts", "My_Post.md")); !os.IsNotExist(err) {
+		t.Error("deleted post still exists")
+	}
+}
+
+func TestPostCommandsBySlug(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":          "title = \"CLI\"\nbase_url = \"https://example.com\"\npermalink = \"/:year/:slug/\"\n",
+		"posts/hello.md":      "---\ntitle: Hello\ndate: 2024-01-02\nslug: hello-world\n---\n\nHello\n",
+		"posts/trip/index.md": "---\ntitle: Trip\ndate: 2024-01-03\n---\n\nTrip\n",
+		"posts/other.md":      "---\ntitle: Other\ndate: 2024-01-04\n---\n\nOther\n",
+	})
+
+	tests := []struct {
+		ref  string
+		name string
+		file string
+	}{
+		{"hello-world", "hello", "posts/hello.md"},
+		{"https://example.com/2024/trip/", "trip", "posts/trip"},
+		{"/2024/other/", "other", "posts/other.md"},
+	}
+	for _, tt := range tests {
+		out, err := runPost(t, "delete", tt.ref, "--yes")
+		if err != nil || out != "Deleted post: "+tt.name+"\n" {
+			t.Errorf("post delete %s = %q, %v", tt.ref, out, err)
+		}
+		if _, err := os.Stat(filepath.FromSlash(tt.file)); !os.IsNotExist(err) {
+			t.Errorf("post delete %s left %s", tt.ref, tt.file)
+		}
+	}
+
+	if _, err := runPost(t, "delete", "hello-world", "--yes"); err == nil || err.Error() != "post not found: hello-world" {
+		t.Errorf("deleting a deleted post error = %v", err)
+	}
+}
+
+func TestContentCommandErrors(t *testing.T) {
+	newTestSite(t, map[string]string{})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"frobnicate", "x"}, `unknown command "frobnicate" for "bazel post"`},
+		{[]string{"edit"}, "accepts 1 arg(s), received 0"},
+		{[]string{"delete", "missing", "--yes"}, "post not found: missing"},
+	}
+	for _, tt := range tests {
+		if _, err := runPost(t, tt.args...); err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("post %q error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestPageOrder(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"pages/about.md":   "---\ntitle: About\nweight: 10\n---\n\nAbout\n",
+		"pages/contact.md": "---\ntitle: Contact\nweight: 20\n---\n\nContact\n",
+		"pages/docs/a.md":  "---\ntitle: A\n---\n\nA\n",
+	})
+	runPage := func(args ...string) (string, error) {
+		return runBazel(t, append([]string{"page"}, args...)...)
+	}
+
+	out, err := runPage("order")
+	if err != nil || out != "about\tAbout\ncontact\tContact\ndocs\tdocs\n" {
+		t.Errorf("page order = %q, %v", out, err)
+	}
+
+	if _, err := runPage("order", "docs/", "contact"); err != nil {
+		t.Fatal(err)
+	}
+	out, _ = runPage("order")
+	if out != "docs\tdocs\ncontact\tContact\nabout\tAbout\n" {
+		t.Errorf("page order after reordering = %q", out)
+	}
+
+	if _, err := runPage("order", "missing"); err == nil || !strings.Contains(err.Error(), "no page or section named missing") {
+		t.Errorf("page order missing error = %v", err)
+	}
+}
+
+func TestChoiceCommands(t *testing.T) {
+	newTestSite(t, map[string]string{"bazel.toml": "title = \"CLI\"\n[theme]\ncolor_scheme = \"nord\"\nfont = \"serif\"\n"})
+
+	out, err := runBazel(t, "font", "list")
+	if err != nil || !strings.Contains(out, "* serif\n") || !strings.Contains(out, "  georgia\n") {
+		t.Errorf("font list = %q, %v", out, err)
+	}
+
+	out, err = runBazel(t, "font", "set", "monospace")
+	if err != nil || out != "Font set to monospace\n" {
+		t.Errorf("font set = %q, %v", out, err)
+	}
+	if value := configValue(t, "theme.font"); value != "monospace" {
+		t.Errorf("theme.font = %q after font set", value)
+	}
+
+	// A failed rebuild warns on stderr, leaving stdout to the result
+	layouts := filepath.Join("themes", "default", "layouts")
+	if err := os.MkdirAll(layouts, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(layouts, "post.html"), []byte(`{{define "main"}}{{.Title}{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err = runBazel(t, "theme", "set", "dracula")
+	if err != nil || out != "Theme set to dracula\n" {
+		t.Errorf("theme set with a broken layout = %q, %v", out, err)
+	}
+
+	if _, err := runBazel(t, "theme", "set", "neon"); err == nil {
+		t.Error("theme set accepted an unknown theme")
+	}
+
+	out, err = runBazel(t, "theme", "list", "--json")
+	var themes []string
+	if err != nil || json.Unmarshal([]byte(out), &themes) != nil || len(themes) == 0 {
+		t.Errorf("theme list --json = %q, %v", out, err)
+	}
+}
+
+func configValue(t *testing.T, key string) string {
+	t.Helper()
+	out, err := runBazel(t, "config", "get", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSuffix(out, "\n")
+}
+
+func TestConfigCommand(t *testing.T) {
+	newTestSite(t, map[string]string{})
+
+	out, err := runBazel(t, "config", "set", "posts_per_page", "7")
+	if err != nil || out != "Set posts_per_page to \"7\"\n" {
+		t.Errorf("config set = %q, %v", out, err)
+	}
+	if value := configValue(t, "posts_per_page"); value != "7" {
+		t.Errorf("config get posts_per_page = %q", value)
+	}
+
+	if _, err := runBazel(t, "config", "get"); err == nil {
+		t.Error("config get without a key succeeded")
+	}
+	if _, err := runBazel(t, "config", "set", "toc", "maybe"); err == nil {
+		t.Error("config set accepted an invalid boolean")
+	}
+}
@@ -3,8 +3,13 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"github.com/yourusername/bazel_blog/internal/config"
 	"github.com/yourusername/bazel_blog/internal/generator"
 	"github.com/yourusername/bazel_blog/internal/registry"
 	"github.com/yourusername/bazel_blog/internal/ui"
@@ -13,90 +18,455 @@ import (
 
 const Version = "1.4.4"
 
+// options holds the global flags, which every command accepts
+var options struct {
+	site    string
+	config  string
+	env     string
+	set     []string
+	quiet   bool
+	verbose bool
+	noColor bool
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		printHelp()
-		return
+	if err := newRootCommand().Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newRootCommand builds the bazel command tree
+func newRootCommand() *cobra.Command {
+	var showVersion bool
+	root := &cobra.Command{
+		Use:   "bazel",
+		Short: "Bazel - Static Site Generator",
+		Long: `Bazel - Static Site Generator
+
+A fast and simple static site generator with Pika-inspired themes.
+Run 'bazel help' for detailed documentation.`,
+		Args:              cobra.NoArgs,
+		SilenceErrors:     true,
+		SilenceUsage:      true,
+		DisableAutoGenTag: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return applyGlobalOptions()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if showVersion {
+				printVersion()
+				return nil
+			}
+			return cmd.Help()
+		},
 	}
+	root.Flags().BoolVarP(&showVersion, "version", "v", false, "Show version information")
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&options.site, "site", "", "Work with the site with this registry name or path")
+	flags.StringVar(&options.config, "config", "", "Config file, relative to the site (default bazel.toml)")
+	flags.StringVar(&options.env, "env", "", "Merge the environment's bazel.<env>.toml over the config")
+	flags.StringArrayVar(&options.set, "set", nil, "Override a setting for this run, as key=value (repeatable)")
+	flags.BoolVarP(&options.quiet, "quiet", "q", false, "Only print errors and requested output")
+	flags.BoolVar(&options.verbose, "verbose", false, "Print more detail about what is happening")
+	flags.BoolVar(&options.noColor, "no-color", false, "Disable colors (also set by NO_COLOR)")
+	root.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	root.RegisterFlagCompletionFunc("site", completeSites)
+	root.RegisterFlagCompletionFunc("env", completeEnvs)
+	root.RegisterFlagCompletionFunc("set", completeOverrides)
+
+	root.AddCommand(
+		newNewCommand(),
+		newSitesCommand(),
+		newContentCommand(postCommands),
+		newContentCommand(pageCommands),
+		newChoiceCommand(themeCommand),
+		newChoiceCommand(fontCommand),
+		newConfigCommand(),
+		newBuildCommand(),
+		newServeCommand(),
+		newPublishCommand(true),
+		newPublishCommand(false),
+		newScheduleCommand(),
+		newUpgradeCommand(),
+		newVersionCommand(),
+		newManCommand(root),
+	)
+	root.SetHelpCommand(newHelpCommand())
+	return root
+}
 
-	command := os.Args[1]
-	switch command {
-	case "-v", "--version", "version":
-		printVersion()
-		return
+// applyGlobalOptions moves into the site given with --site and points the
+// config package at the file given with --config
+func applyGlobalOptions() error {
+	if options.noColor {
+		ui.NoColor = true
+	}
 
-	case "new":
-		if len(os.Args) < 4 || os.Args[2] != "site" {
-			fmt.Println("Usage: bazel new site <site-name>")
-			os.Exit(1)
-		}
-		siteName := os.Args[3]
-		err := generator.NewSite(siteName)
+	if options.site != "" {
+		dir, err := resolveSite(options.site)
 		if err != nil {
-			fmt.Printf("Error creating site: %v\n", err)
-			os.Exit(1)
+			return err
+		}
+		if err := os.Chdir(dir); err != nil {
+			return fmt.Errorf("failed to change to site directory: %w", err)
 		}
-		fmt.Printf("Created new site: %s\n", siteName)
+		verbosef("Working with site in %s\n", dir)
+	}
 
-	case "sites":
-		listSites()
+	if options.config != "" {
+		config.Path = options.config
+		verbosef("Using config file %s\n", config.Path)
+	}
 
-	case "post":
-		runWithSiteSelection(func() error {
-			ui.RunPostMenu()
-			return nil
-		})
+	if options.env != "" {
+		config.Env = options.env
+		verbosef("Using environment %s from %s\n", config.Env, config.EnvPath(config.Env))
+	}
 
-	case "page":
-		runWithSiteSelection(func() error {
-			ui.RunPageMenu()
-			return nil
-		})
+	config.Overrides = options.set
+	return nil
+}
 
-	case "theme":
-		runWithSiteSelection(func() error {
-			ui.RunThemeMenu()
-			return nil
-		})
+// resolveSite returns the absolute directory of a site given by its name in
+// the registry or by its path
+func resolveSite(site string) (string, error) {
+	if reg, err := registry.LoadRegistry(); err == nil {
+		if found, err := reg.FindSiteByName(site); err == nil {
+			reg.UpdateLastUsed(found.Path)
+			reg.Save()
+			return filepath.Abs(found.Path)
+		}
+	}
+	if info, err := os.Stat(site); err == nil && info.IsDir() {
+		return filepath.Abs(site)
+	}
+	return "", fmt.Errorf("no site named %q in the registry and no such directory", site)
+}
 
-	case "font":
-		runWithSiteSelection(func() error {
-			ui.RunFontMenu()
-			return nil
-		})
+// statusf prints progress and success messages, which --quiet silences
+func statusf(format string, args ...interface{}) {
+	if !options.quiet {
+		fmt.Printf(format, args...)
+	}
+}
+
+// verbosef prints details that are only shown with --verbose
+func verbosef(format string, args ...interface{}) {
+	if options.verbose {
+		fmt.Printf(format, args...)
+	}
+}
+
+func isInBazelSite() bool {
+	_, err := os.Stat(config.Path)
+	return err == nil
+}
+
+// withSite runs fn in the site given with --site or in the current
+// directory, or else in a site picked from the registry with the selector
+func withSite(fn func() error) error {
+	// Check if we're in a Bazel site directory
+	if isInBazelSite() {
+		// We're in a site directory, run the command directly
+		return fn()
+	}
+	if options.site != "" {
+		return fmt.Errorf("%s has no %s", options.site, config.Path)
+	}
+
+	// We're not in a site directory, show site selector
+	selectedSite, err := ui.RunSiteSelector()
+	if err != nil {
+		return fmt.Errorf("failed to select site: %w", err)
+	}
+
+	if selectedSite == nil {
+		statusf("No site selected\n")
+		return nil
+	}
+
+	// Change to the selected site directory
+	originalDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	err = os.Chdir(selectedSite.Path)
+	if err != nil {
+		return fmt.Errorf("failed to change to site directory: %w", err)
+	}
+	defer os.Chdir(originalDir)
+
+	// Update the site's last used time
+	reg, err := registry.LoadRegistry()
+	if err == nil {
+		reg.UpdateLastUsed(selectedSite.Path)
+		reg.Save()
+	}
+
+	statusf("Working with site: %s\n", selectedSite.Name)
+	return fn()
+}
+
+// inSite runs fn in the site given with --site or in the current directory.
+// Unlike withSite it never prompts for a site, so scripts fail instead of
+// waiting for input.
+func inSite(fn func() error) error {
+	if isInBazelSite() {
+		return fn()
+	}
+	if options.site != "" {
+		return fmt.Errorf("%s has no %s", options.site, config.Path)
+	}
+	return fmt.Errorf("not in a Bazel site (no %s in the current directory), use --site to pick one", config.Path)
+}
+
+// completeSites completes --site with the names in the registry
+func completeSites(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	reg, err := registry.LoadRegistry()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, site := range reg.GetSites() {
+		names = append(names, site.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEnvs completes --env with the environments that have an overlay
+// file next to the config
+func completeEnvs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if applyGlobalOptions() != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	pattern := config.EnvPath("*")
+	prefix := strings.TrimSuffix(pattern, "*.toml")
+	files, _ := filepath.Glob(pattern)
+	var envs []string
+	for _, file := range files {
+		envs = append(envs, strings.TrimSuffix(strings.TrimPrefix(file, prefix), ".toml"))
+	}
+	return envs, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeOverrides completes --set with the config keys, followed by "="
+func completeOverrides(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if applyGlobalOptions() != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	cfg, err := config.LoadBaseConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var keys []string
+	for _, key := range cfg.Keys() {
+		keys = append(keys, key+"=")
+	}
+	return keys, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
 
-	case "config":
-		runWithSiteSelection(func() error {
-			ui.RunConfigMenu()
+func newNewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "Create a new site",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "site <name>",
+		Short: "Create a new site with sample posts, an about page and a config",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := generator.NewSite(args[0]); err != nil {
+				return fmt.Errorf("failed to create site: %w", err)
+			}
+			statusf("Created new site: %s\n", args[0])
 			return nil
-		})
+		},
+	})
+	return cmd
+}
 
-	case "build":
-		runWithSiteSelection(func() error {
-			return generator.BuildSite()
-		})
+func newSitesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sites",
+		Short: "List registered sites",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listSites()
+		},
+	}
+}
 
-	case "serve":
-		runWithSiteSelection(func() error {
-			return generator.StartDevServer()
-		})
+func newBuildCommand() *cobra.Command {
+	var opts generator.BuildOptions
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Build the site into public/",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSite(func() error {
+				start := time.Now()
+				if err := generator.BuildSiteWithOptions(opts); err != nil {
+					return err
+				}
+				verbosef("Built in %s\n", time.Since(start).Round(time.Millisecond))
+				statusf("Site built successfully!\n")
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&opts.Clean, "clean", false, "Discard the previous output and build cache")
+	cmd.Flags().BoolVar(&opts.Strict, "strict", false, "Fail on malformed frontmatter, unknown keys or bad dates")
+	cmd.Flags().BoolVar(&opts.Future, "future", false, "Include posts dated in the future")
+	return cmd
+}
 
-	case "upgrade":
-		runWithSiteSelection(func() error {
-			return upgrade.RunUpgrade()
-		})
+func newServeCommand() *cobra.Command {
+	var opts generator.BuildOptions
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start the development server with live reload",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSite(func() error {
+				return generator.StartDevServerWithOptions(opts)
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&opts.Drafts, "drafts", false, "Include draft posts and pages")
+	cmd.Flags().BoolVar(&opts.Future, "future", false, "Include posts dated in the future")
+	return cmd
+}
 
-	case "help":
-		printDetailedHelp()
+// newPublishCommand returns `bazel publish` or `bazel unpublish`
+func newPublishCommand(publish bool) *cobra.Command {
+	use, short := "publish", "Publish a draft"
+	if !publish {
+		use, short = "unpublish", "Move content back to drafts"
+	}
+	return &cobra.Command{
+		Use:       use + " <post|page> <name>",
+		Short:     short,
+		Args:      cobra.MatchAll(cobra.ExactArgs(2), kindArg),
+		ValidArgs: []string{"post", "page"},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return []string{"post", "page"}, cobra.ShellCompDirectiveNoFileComp
+			}
+			c := postCommands
+			if args[0] == "page" {
+				c = pageCommands
+			}
+			return completeContent(c, publish)(cmd, args[1:], toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind, name := args[0], args[1]
+			return withSite(func() error {
+				if err := setPublished(kind, name, publish); err != nil {
+					return err
+				}
+				if publish {
+					statusf("Published %s: %s\n", kind, name)
+				} else {
+					statusf("Moved %s to drafts: %s\n", kind, name)
+				}
+				return nil
+			})
+		},
+	}
+}
 
-	default:
-		printHelp()
+// kindArg checks that the first argument is "post" or "page"
+func kindArg(cmd *cobra.Command, args []string) error {
+	if args[0] != "post" && args[0] != "page" {
+		return fmt.Errorf("expected post or page, got %q", args[0])
 	}
+	return nil
 }
 
-func isInBazelSite() bool {
-	_, err := os.Stat("bazel.toml")
-	return err == nil
+func newScheduleCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schedule",
+		Short: "List posts that go live or expire later",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSite(printSchedule)
+		},
+	}
+}
+
+func newUpgradeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade site to latest version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSite(upgrade.RunUpgrade)
+		},
+	}
+}
+
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Show version information",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			printVersion()
+		},
+	}
+}
+
+// newManCommand writes a man page for every command of root
+func newManCommand(root *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:   "man [directory]",
+		Short: "Generate man pages (default directory: man)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "man"
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create man page directory: %w", err)
+			}
+			header := &doc.GenManHeader{
+				Title:   "BAZEL",
+				Section: "1",
+				Source:  "Bazel " + Version,
+				Manual:  "Bazel Manual",
+			}
+			if err := doc.GenManTree(root, header, dir); err != nil {
+				return fmt.Errorf("failed to generate man pages: %w", err)
+			}
+			statusf("Wrote man pages to %s\n", dir)
+			return nil
+		},
+	}
+}
+
+// newHelpCommand shows the detailed guide for `bazel help`, and the generated
+// help of a command for `bazel help <command>`
+func newHelpCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "help [command]",
+		Short: "Show detailed help, or help for a command",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				printDetailedHelp()
+				return nil
+			}
+			target, _, err := cmd.Root().Find(args)
+			if err != nil || target == cmd.Root() {
+				return fmt.Errorf("unknown help topic %q", strings.Join(args, " "))
+			}
+			return target.Help()
+		},
+	}
 }
 
 func printVersion() {
@@ -113,26 +483,6 @@ func printVersion() {
 	fmt.Println("• Automatic footer with BazelBlog attribution and social links")
 }
 
-func printHelp() {
-	fmt.Println("Bazel - Static Site Generator")
-	fmt.Println("")
-	fmt.Println("Commands:")
-	fmt.Println("  new site <name>   Create a new site")
-	fmt.Println("  post              Interactive post management")
-	fmt.Println("  page              Interactive page management")
-	fmt.Println("  theme             Select site theme")
-	fmt.Println("  font              Select site font")
-	fmt.Println("  config            Configure site settings")
-	fmt.Println("  build             Build the site")
-	fmt.Println("  serve             Start dev server")
-	fmt.Println("  upgrade           Upgrade site to latest version")
-	fmt.Println("  sites             List registered sites")
-	fmt.Println("  version           Show version information")
-	fmt.Println("  help              Show detailed help")
-	fmt.Println("")
-	fmt.Println("Run 'bazel help' for detailed documentation.")
-}
-
 func printDetailedHelp() {
 	fmt.Println("🏗️  Bazel - Static Site Generator")
 	fmt.Println("")
@@ -151,14 +501,22 @@ func printDetailedHelp() {
 	fmt.Println("   Interactive post management menu:")
 	fmt.Println("   • New: Create a new post in your default editor")
 	fmt.Println("   • Edit: Select and edit existing posts")
-	fmt.Println("   • Drafts: Manage draft posts")
+	fmt.Println("   • Drafts: List and publish draft posts")
+	fmt.Println("   Or without the menu, for scripts and editor plugins:")
+	fmt.Println("   • bazel post new \"Title\" [--no-edit]")
+	fmt.Println("   • bazel post list [--drafts] [--json]")
+	fmt.Println("   • bazel post edit <name>")
+	fmt.Println("   • bazel post delete <name> [--yes]")
+	fmt.Println("   • bazel post publish|unpublish <name>")
 	fmt.Println("")
 	fmt.Println("📄 bazel page")
 	fmt.Println("   Interactive page management menu:")
 	fmt.Println("   • New: Create a new page in your default editor")
 	fmt.Println("   • Edit: Select and edit existing pages")
-	fmt.Println("   • Drafts: Manage draft pages")
+	fmt.Println("   • Drafts: List and publish draft pages")
 	fmt.Println("   • Organize: Reorder page navigation")
+	fmt.Println("   The post subcommands work for pages too, plus:")
+	fmt.Println("   • bazel page order [<name>...]  Show or set the navigation order")
 	fmt.Println("")
 	fmt.Println("🎨 bazel theme")
 	fmt.Println("   Interactive theme selector with live preview:")
@@ -168,12 +526,14 @@ func printDetailedHelp() {
 	fmt.Println("   • nord - Arctic blue theme")
 	fmt.Println("   • tokyo-night - Neon night theme")
 	fmt.Println("   • 3li7e - Retro green-on-black CRT monitor theme")
+	fmt.Println("   'bazel theme list' and 'bazel theme set <name>' skip the menu.")
 	fmt.Println("")
 	fmt.Println("🔤 bazel font")
 	fmt.Println("   Interactive font selector with preview:")
 	fmt.Println("   • pika-serif (default) - Source Serif 4")
 	fmt.Println("   • system - System fonts")
 	fmt.Println("   • serif, monospace, arial, helvetica, georgia, times")
+	fmt.Println("   'bazel font list' and 'bazel font set <name>' skip the menu.")
 	fmt.Println("")
 	fmt.Println("⚙️  bazel config")
 	fmt.Println("   Comprehensive configuration menu:")
@@ -182,19 +542,42 @@ func printDetailedHelp() {
 	fmt.Println("   • Font: Select typography with preview")
 	fmt.Println("   • Social Links: Configure social media profiles")
 	fmt.Println("   • All site configuration in one place")
+	fmt.Println("   'bazel config get <key>' and 'bazel config set <key> <value>' read and")
+	fmt.Println("   change single settings by their bazel.toml name, e.g. title,")
+	fmt.Println("   theme.font, socials.github or markdown.footnotes.")
+	fmt.Println("   'bazel config show --resolved' lists every setting after --env,")
+	fmt.Println("   BAZEL_ environment variables and --set, with where it came from.")
 	fmt.Println("")
 	fmt.Println("🔧 bazel build")
 	fmt.Println("   Build your site for production:")
 	fmt.Println("   • Processes all posts and pages")
 	fmt.Println("   • Generates CSS with selected theme")
-	fmt.Println("   • Creates RSS feed")
-	fmt.Println("   • Outputs to public/ directory")
+	fmt.Println("   • Creates RSS, Atom and JSON feeds")
+	fmt.Println("   • Outputs to public/, or output_dir in bazel.toml")
+	fmt.Println("   • Only rewrites files that changed since the last build")
+	fmt.Println("   • --clean discards the previous output and build cache")
+	fmt.Println("   • --strict fails on malformed frontmatter, unknown keys or bad dates")
+	fmt.Println("   • Leaves out posts dated in the future; --future includes them")
 	fmt.Println("")
 	fmt.Println("🚀 bazel serve")
 	fmt.Println("   Start development server:")
 	fmt.Println("   • Serves site at http://localhost:3000")
 	fmt.Println("   • Live reload on file changes")
 	fmt.Println("   • Perfect for development and preview")
+	fmt.Println("   • --drafts includes draft posts and pages")
+	fmt.Println("   • --future includes posts dated in the future")
+	fmt.Println("")
+	fmt.Println("📅 bazel schedule")
+	fmt.Println("   List posts that go live or expire after now, by 'date' or")
+	fmt.Println("   'publish_date' and 'expiry_date' in their frontmatter.")
+	fmt.Println("")
+	fmt.Println("📝 bazel publish <post|page> <name>")
+	fmt.Println("   Publish a draft so it is included in 'bazel build'.")
+	fmt.Println("   Drafts live in posts/drafts/ and pages/drafts/, or are marked")
+	fmt.Println("   with 'draft: true' in their frontmatter.")
+	fmt.Println("")
+	fmt.Println("📥 bazel unpublish <post|page> <name>")
+	fmt.Println("   Move published content back into drafts.")
 	fmt.Println("")
 	fmt.Println("🔄 bazel upgrade")
 	fmt.Println("   Upgrade site to latest version:")
@@ -203,6 +586,27 @@ func printDetailedHelp() {
 	fmt.Println("   • Maintains backward compatibility")
 	fmt.Println("   • Automatically rebuilds site after upgrade")
 	fmt.Println("")
+	fmt.Println("🐚 bazel completion <bash|zsh|fish>")
+	fmt.Println("   Print a shell completion script, e.g.:")
+	fmt.Println("   • source <(bazel completion bash)")
+	fmt.Println("")
+	fmt.Println("📖 bazel man [directory]")
+	fmt.Println("   Write man pages for every command (default: ./man)")
+	fmt.Println("")
+	fmt.Println("🌐 GLOBAL FLAGS:")
+	fmt.Println("")
+	fmt.Println("   --site <name|path>  Work with a registered site or a directory")
+	fmt.Println("                       instead of prompting for one")
+	fmt.Println("   --config <file>     Use another config file than bazel.toml")
+	fmt.Println("   --env <name>        Merge bazel.<name>.toml over the config, e.g.")
+	fmt.Println("                       for a staging base_url")
+	fmt.Println("   --set <key=value>   Override a setting for this run, e.g.")
+	fmt.Println("                       --set base_url=https://preview.example.com")
+	fmt.Println("   -q, --quiet         Only print errors and requested output")
+	fmt.Println("   --verbose           Print more detail, such as build times")
+	fmt.Println("   --no-color          Disable colors (also set by NO_COLOR)")
+	fmt.Println("   Every command also takes --help.")
+	fmt.Println("")
 	fmt.Println("📁 DIRECTORY STRUCTURE:")
 	fmt.Println("")
 	fmt.Println("   your-site/")
@@ -250,69 +654,51 @@ func printDetailedHelp() {
 	fmt.Println("Happy blogging! 🎉")
 }
 
-func runWithSiteSelection(fn func() error) {
-	// Check if we're in a Bazel site directory
-	if isInBazelSite() {
-		// We're in a site directory, run the command directly
-		err := fn()
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
-		}
-		return
-	}
-
-	// We're not in a site directory, show site selector
-	selectedSite, err := ui.RunSiteSelector()
-	if err != nil {
-		fmt.Printf("Error selecting site: %v\n", err)
-		os.Exit(1)
-	}
-
-	if selectedSite == nil {
-		fmt.Println("No site selected")
-		return
-	}
-
-	// Change to the selected site directory
-	originalDir, err := os.Getwd()
+// printSchedule lists the posts that go live or expire in the future
+func printSchedule() error {
+	scheduled, err := generator.ListScheduled()
 	if err != nil {
-		fmt.Printf("Error getting current directory: %v\n", err)
-		os.Exit(1)
+		return err
 	}
-
-	err = os.Chdir(selectedSite.Path)
-	if err != nil {
-		fmt.Printf("Error changing to site directory: %v\n", err)
-		os.Exit(1)
+	if len(scheduled) == 0 {
+		fmt.Println("No scheduled posts.")
+		return nil
 	}
 
-	// Update the site's last used time
-	reg, err := registry.LoadRegistry()
-	if err == nil {
-		reg.UpdateLastUsed(selectedSite.Path)
-		reg.Save()
+	const layout = "Mon Jan 2, 2006 15:04"
+	fmt.Println("📅 Scheduled posts:")
+	fmt.Println("")
+	now := time.Now()
+	for _, post := range scheduled {
+		fmt.Printf("%s (%s)\n", post.Title, post.Filename)
+		if post.PublishAt.After(now) {
+			fmt.Printf("   🚀 Goes live: %s\n", post.PublishAt.Format(layout))
+		}
+		if !post.ExpiresAt.IsZero() {
+			fmt.Printf("   ⏹  Expires:   %s\n", post.ExpiresAt.Format(layout))
+		}
 	}
+	return nil
+}
 
-	fmt.Printf("Working with site: %s\n", selectedSite.Name)
-
-	// Run the command
-	err = fn()
-
-	// Change back to original directory
-	os.Chdir(originalDir)
-
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+// setPublished publishes or unpublishes the named post or page
+func setPublished(kind, name string, publish bool) error {
+	switch {
+	case kind == "post" && publish:
+		return generator.PublishPost(name)
+	case kind == "post":
+		return generator.UnpublishPost(name)
+	case publish:
+		return generator.PublishPage(name)
+	default:
+		return generator.UnpublishPage(name)
 	}
 }
 
-func listSites() {
+func listSites() error {
 	reg, err := registry.LoadRegistry()
 	if err != nil {
-		fmt.Printf("Error loading site registry: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to load site registry: %w", err)
 	}
 
 	// Clean up invalid sites
@@ -323,7 +709,7 @@ func listSites() {
 	if len(sites) == 0 {
 		fmt.Println("No Bazel sites found in registry.")
 		fmt.Println("Create a new site with: bazel new site <name>")
-		return
+		return nil
 	}
 
 	fmt.Println("🏗️  Registered Bazel Sites:")
@@ -342,7 +728,9 @@ func listSites() {
 	fmt.Printf("Total: %d site(s)\n", len(sites))
 	fmt.Println("")
 	fmt.Println("Use 'bazel post', 'bazel build', etc. to work with a site.")
-	fmt.Println("If not in a site directory, you'll be prompted to select one.")
+	fmt.Println("If not in a site directory, you'll be prompted to select one,")
+	fmt.Println("or pick one with --site <name>.")
+	return nil
 }
 
 func formatLastUsed(lastUsed time.Time) string {
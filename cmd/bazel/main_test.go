@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGlobalFlags(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"blog/bazel.toml":     "title = \"Blog\"\n",
+		"blog/staging.toml":   "title = \"Staging\"\n",
+		"blog/bazel.dev.toml": "title = \"Dev\"\n",
+		"blog/posts/hello.md": "---\ntitle: Hello\ndate: 2024-01-02\n---\n\nHello\n",
+	})
+	os.Remove("bazel.toml") // The current directory is not a site
+	dir, _ := os.Getwd()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"site path", []string{"--site", "blog", "config", "get", "title"}, "Blog\n"},
+		{"config file", []string{"--site", "blog", "--config", "staging.toml", "config", "get", "title"}, "Staging\n"},
+		{"environment", []string{"--site", "blog", "--env", "dev", "config", "get", "title"}, "Dev\n"},
+		{"quiet", []string{"--site", "blog", "-q", "post", "new", "Quiet", "--no-edit"}, ""},
+		{"requested output with quiet", []string{"--site", "blog", "-q", "post", "list"}, "Quiet\tQuiet\nhello\tHello\n"},
+		{"verbose", []string{"--site", "blog", "--verbose", "config", "get", "title"}, "Working with site in " + filepath.Join(dir, "blog") + "\nBlog\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(dir)
+			out, err := runBazel(t, tt.args...)
+			if err != nil || out != tt.want {
+				t.Errorf("bazel %s = %q, %v, want %q", strings.Join(tt.args, " "), out, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobalFlagErrors(t *testing.T) {
+	newTestSite(t, map[string]string{})
+	os.Remove("bazel.toml")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"post", "list"}, "not in a Bazel site (no bazel.toml in the current directory), use --site to pick one"},
+		{[]string{"--site", "nowhere", "post", "list"}, `no site named "nowhere" in the registry and no such directory`},
+		{[]string{"--quiet", "--verbose", "post", "list"}, "if any flags in the group [quiet verbose] are set none of the others can be"},
+		{[]string{"bogus"}, `unknown command "bogus" for "bazel"`},
+	}
+	for _, tt := range tests {
+		if _, err := runBazel(t, tt.args...); err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("bazel %s error = %v, want %q", strings.Join(tt.args, " "), err, tt.want)
+		}
+	}
+}
+
+func TestCompletionAndManPages(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/hello.md":        "---\ntitle: Hello\n---\n\nHello\n",
+		"posts/drafts/later.md": "---\ntitle: Later\n---\n\nLater\n",
+	})
+
+	out, err := runBazel(t, "completion", "bash")
+	if err != nil || !strings.Contains(out, "bash completion") {
+		t.Errorf("completion bash = %.80q, %v", out, err)
+	}
+
+	out, err = runBazel(t, "__complete", "post", "publish", "")
+	if err != nil || !strings.HasPrefix(out, "later\n") || strings.Contains(out, "hello") {
+		t.Errorf("completing post publish = %q, %v", out, err)
+	}
+	out, err = runBazel(t, "__complete", "config", "get", "theme.")
+	if err != nil || !strings.Contains(out, "theme.color_scheme\n") {
+		t.Errorf("completing config get = %q, %v", out, err)
+	}
+
+	if _, err := runBazel(t, "man", "manpages"); err != nil {
+		t.Fatal(err)
+	}
+	for _, page := range []string{"bazel.1", "bazel-post-new.1", "bazel-config-set.1"} {
+		if _, err := os.Stat(filepath.Join("manpages", page)); err != nil {
+			t.Errorf("man page %s was not written: %v", page, err)
+		}
+	}
+}
+
+func TestConfigOverrides(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":         "title = \"Blog\"\ndescription = \"Mine\"\nbase_url = \"https://example.com\"\n",
+		"bazel.preview.toml": "base_url = \"https://preview.example.com\"\n",
+	})
+	t.Setenv("BAZEL_DESCRIPTION", "From env")
+
+	out, err := runBazel(t, "--env", "preview", "--set", "title=Overridden", "config", "show", "--resolved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"title ", "--set ", "Overridden\n",
+		"BAZEL_DESCRIPTION", "From env\n",
+		"bazel.preview.toml", "https://preview.example.com\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("config show --resolved does not contain %q:\n%s", want, out)
+		}
+	}
+
+	// Overrides are not written back when a setting is saved
+	if _, err := runBazel(t, "--set", "title=Overridden", "config", "set", "posts_per_page", "4"); err != nil {
+		t.Fatal(err)
+	}
+	out, err = runBazel(t, "config", "show")
+	if err != nil || !strings.Contains(out, `title = "Blog"`) || !strings.Contains(out, `description = "Mine"`) ||
+		!strings.Contains(out, "posts_per_page = 4") {
+		t.Errorf("config show after config set = %q, %v", out, err)
+	}
+
+	if _, err := runBazel(t, "--set", "posts_per_page=lots", "config", "get", "title"); err == nil ||
+		!strings.Contains(err.Error(), "--set posts_per_page") {
+		t.Errorf("invalid --set error = %v", err)
+	}
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -15,9 +18,109 @@ type Config struct {
 	Theme       ThemeConfig       `toml:"theme"`
 	Socials     map[string]string `toml:"socials"`
 	Editor      string            `toml:"editor"`
+
+	// OutputDir is where the site is built, "public" by default
+	OutputDir string `toml:"output_dir,omitempty"`
+
+	// ContentDir holds the posts/ and pages/ directories, the site
+	// directory by default
+	ContentDir string `toml:"content_dir,omitempty"`
+
+	// StaticDir is copied into the output as-is, "static" by default
+	StaticDir string `toml:"static_dir,omitempty"`
+
+	// PostsPerPage splits the home page into /page/2/, /page/3/, ...
+	// Zero lists every post on a single page.
+	PostsPerPage int `toml:"posts_per_page,omitempty"`
+
+	// Permalink is the URL pattern for posts, e.g. "/:year/:month/:slug/".
+	// Supports :year, :month, :day, :slug, :title and :filename.
+	// A trailing slash writes the post to <path>/index.html.
+	Permalink string `toml:"permalink,omitempty"`
+
+	// Redirects additionally writes post aliases as a server redirect file:
+	// "netlify" for public/_redirects or "nginx" for public/redirects.map.
+	Redirects string `toml:"redirects,omitempty"`
+
+	// TOC shows a table of contents on every post; the `toc` frontmatter
+	// field overrides it per post.
+	TOC bool `toml:"toc,omitempty"`
+
+	// Timezone is the IANA time zone, such as "Europe/Berlin", that post
+	// dates without a zone are read in. The local time zone by default.
+	Timezone string `toml:"timezone,omitempty"`
+
+	// SummaryLength is the number of words in automatic post summaries
+	SummaryLength int `toml:"summary_length,omitempty"`
+
+	Author AuthorConfig `toml:"author,omitempty"`
+	Feed   FeedConfig   `toml:"feed,omitempty"`
+	Robots RobotsConfig `toml:"robots,omitempty"`
+
+	Highlight HighlightConfig `toml:"highlight,omitempty"`
+	Markdown  MarkdownConfig  `toml:"markdown,omitempty"`
+
+	// Menu adds links to the navigation menus, keyed by menu name. Pages and
+	// sections join the "main" menu, which is the header navigation.
+	Menu map[string][]MenuEntry `toml:"menu,omitempty"`
+}
+
+// MenuEntry is a link in a navigation menu, set with [[menu.main]]
+type MenuEntry struct {
+	Name   string `toml:"name"`
+	URL    string `toml:"url"`             // External URL, or a path on the site such as "/tags/"
+	Weight int    `toml:"weight,omitzero"` // Lower weights come first, unweighted entries last
+}
+
+// AuthorConfig identifies the site author in feeds
+type AuthorConfig struct {
+	Name  string `toml:"name,omitempty"`
+	Email string `toml:"email,omitempty"`
+	URL   string `toml:"url,omitempty"`
+}
+
+// FeedConfig controls the RSS, Atom and JSON feeds
+type FeedConfig struct {
+	Limit    int    `toml:"limit,omitempty"`    // Maximum posts per feed, zero for all
+	Content  string `toml:"content,omitempty"`  // "full" (default) or "summary"
+	Language string `toml:"language,omitempty"` // Defaults to "en-us"
+}
+
+// HighlightConfig controls syntax highlighting of fenced code blocks
+type HighlightConfig struct {
+	Style       string `toml:"style,omitempty"`        // Chroma style, defaults to one matching the color scheme
+	LineNumbers bool   `toml:"line_numbers,omitempty"` // Number the lines of every code block
+}
+
+// MarkdownConfig toggles markdown extensions and rendering options
+type MarkdownConfig struct {
+	Footnotes       bool  `toml:"footnotes,omitempty"`        // [^1] references and notes
+	DefinitionLists bool  `toml:"definition_lists,omitempty"` // Term lines followed by ": definition"
+	Typographer     bool  `toml:"typographer,omitempty"`      // Smart quotes, dashes and ellipses
+	HardWraps       *bool `toml:"hard_wraps,omitempty"`       // Line breaks within paragraphs are kept, defaults to true
+	Unsafe          *bool `toml:"unsafe,omitempty"`           // Raw HTML is passed through, defaults to true
+	Math            bool  `toml:"math,omitempty"`             // $inline$ and $$display$$ math, typeset by KaTeX
+}
+
+// HardWrapsEnabled reports whether line breaks within paragraphs are kept
+func (m MarkdownConfig) HardWrapsEnabled() bool {
+	return m.HardWraps == nil || *m.HardWraps
+}
+
+// UnsafeEnabled reports whether raw HTML in markdown is passed through
+func (m MarkdownConfig) UnsafeEnabled() bool {
+	return m.Unsafe == nil || *m.Unsafe
+}
+
+// RobotsConfig is written to robots.txt, which always links the sitemap
+type RobotsConfig struct {
+	UserAgent string   `toml:"user_agent,omitempty"` // Defaults to "*"
+	Allow     []string `toml:"allow,omitempty"`
+	Disallow  []string `toml:"disallow,omitempty"`
 }
 
 type ThemeConfig struct {
+	Name        string `toml:"name,omitempty"` // Layouts are read from themes/<name>/layouts
 	ColorScheme string `toml:"color_scheme"`
 	Font        string `toml:"font"`
 }
@@ -88,25 +191,182 @@ var SocialPlatforms = []string{
 	"email",
 }
 
+// Path is the config file of the site, relative to the site directory unless
+// it is absolute. The --config flag changes it.
+var Path = "bazel.toml"
+
+// Env names the build environment, such as "production" or "staging", whose
+// overlay file is merged over the config. The --env flag sets it.
+var Env string
+
+// EnvPath returns the overlay file of an environment, which sits next to the
+// config file: bazel.staging.toml for bazel.toml and "staging"
+func EnvPath(env string) string {
+	return strings.TrimSuffix(Path, filepath.Ext(Path)) + "." + env + ".toml"
+}
+
+// LoadConfig reads the config file with the overlay of the current
+// environment merged over it, then applies BAZEL_ environment variables and
+// --set overrides. Settings in the overlay replace those of the config file,
+// and tables such as [theme] are merged key by key.
 func LoadConfig() (*Config, error) {
-	configPath := "bazel.toml"
+	config, _, err := LoadResolvedConfig()
+	return config, err
+}
+
+// LoadResolvedConfig is LoadConfig that also reports where each setting
+// came from: a config file, an environment variable, "--set" or "default".
+func LoadResolvedConfig() (*Config, map[string]string, error) {
+	config, meta, err := loadBaseConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	sources := make(map[string]string)
+	config.recordSources(sources, meta, Path)
+
+	if Env != "" {
+		envPath := EnvPath(Env)
+		if _, err := os.Stat(envPath); os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("no config for environment %q, expected %s", Env, envPath)
+		}
+		meta, err := toml.DecodeFile(envPath, config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", envPath, err)
+		}
+		config.recordSources(sources, meta, envPath)
+	}
+
+	if err := config.applyEnvVars(sources); err != nil {
+		return nil, nil, err
+	}
+	if err := config.applyOverrides(sources); err != nil {
+		return nil, nil, err
+	}
+
+	for _, key := range config.Keys() {
+		if sources[key] == "" {
+			sources[key] = "default"
+		}
+	}
+
+	if _, err := config.Location(); err != nil {
+		return nil, nil, fmt.Errorf("invalid timezone from %s: %w", sources["timezone"], err)
+	}
+	if err := config.validateOutputDir(); err != nil {
+		return nil, nil, fmt.Errorf("invalid output_dir from %s: %w", sources["output_dir"], err)
+	}
+	return config, sources, nil
+}
+
+// LoadBaseConfig reads the config file without any environment overlay or
+// overrides. Use it to change and Save the config, so those settings are not
+// written into the config file.
+func LoadBaseConfig() (*Config, error) {
+	config, _, err := loadBaseConfig()
+	return config, err
+}
+
+func loadBaseConfig() (*Config, toml.MetaData, error) {
+	configPath := Path
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// Return default config if no config file exists
 		config := DefaultConfig
-		return &config, nil
+		config.Socials = make(map[string]string) // Not shared with DefaultConfig
+		return &config, toml.MetaData{}, nil
 	}
 
 	var config Config
-	_, err := toml.DecodeFile(configPath, &config)
+	meta, err := toml.DecodeFile(configPath, &config)
+	if err != nil {
+		return nil, meta, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return &config, meta, nil
+}
+
+// OutputPath returns the directory the site is built into
+func (c *Config) OutputPath() string {
+	if c.OutputDir == "" {
+		return "public"
+	}
+	return filepath.Clean(c.OutputDir)
+}
+
+// Location returns the time zone post dates without a zone are read in
+func (c *Config) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+// themesDir holds the site's themes, next to the config file
+const themesDir = "themes"
+
+// validateOutputDir rejects an output directory that would take the site's
+// sources with it when a clean build removes it: the site directory itself,
+// one of its parents, or a directory holding content, static files or themes.
+func (c *Config) validateOutputDir() error {
+	output, err := filepath.Abs(c.OutputPath())
+	if err != nil {
+		return err
+	}
+	site, err := filepath.Abs(".")
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		return err
+	}
+	if containsPath(output, site) {
+		return fmt.Errorf("%q is the site directory or one of its parents", c.OutputDir)
 	}
 
-	return &config, nil
+	sources := []struct{ name, path string }{
+		{"content_dir", c.ContentDir},
+		{"posts", c.PostsPath()},
+		{"pages", c.PagesPath()},
+		{"static_dir", c.StaticPath()},
+		{"themes", themesDir},
+	}
+	for _, source := range sources {
+		dir, err := filepath.Abs(source.path)
+		if err != nil {
+			return err
+		}
+		if containsPath(output, dir) {
+			return fmt.Errorf("%q would overwrite %s (%s)", c.OutputDir, source.name, source.path)
+		}
+	}
+	return nil
+}
+
+// containsPath reports whether path is dir or lies inside it
+func containsPath(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// PostsPath returns the directory posts are read from
+func (c *Config) PostsPath() string {
+	return filepath.Join(c.ContentDir, "posts")
+}
+
+// PagesPath returns the directory pages are read from
+func (c *Config) PagesPath() string {
+	return filepath.Join(c.ContentDir, "pages")
+}
+
+// StaticPath returns the directory copied into the output as-is
+func (c *Config) StaticPath() string {
+	if c.StaticDir == "" {
+		return "static"
+	}
+	return filepath.Clean(c.StaticDir)
 }
 
 func (c *Config) Save() error {
-	file, err := os.Create("bazel.toml")
+	file, err := os.Create(Path)
 	if err != nil {
 		return fmt.Errorf("failed to create config file: %w", err)
 	}
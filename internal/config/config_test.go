@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// withEnv selects an environment for one test
+func withEnv(t *testing.T, env string) {
+	t.Helper()
+	old := Env
+	Env = env
+	t.Cleanup(func() { Env = old })
+}
+
+func TestEnvironmentOverlay(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"bazel.toml":         "title = \"Blog\"\nbase_url = \"https://example.com\"\n[theme]\ncolor_scheme = \"nord\"\nfont = \"serif\"\n",
+		"bazel.staging.toml": "base_url = \"https://staging.example.com\"\n[theme]\nfont = \"monospace\"\n",
+	})
+	withEnv(t, "staging")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Title != "Blog" || cfg.BaseURL != "https://staging.example.com" {
+		t.Errorf("title %q, base_url %q, want the overlay's base_url only", cfg.Title, cfg.BaseURL)
+	}
+	if cfg.Theme.ColorScheme != "nord" || cfg.Theme.Font != "monospace" {
+		t.Errorf("theme = %+v, want tables merged key by key", cfg.Theme)
+	}
+
+	base, err := LoadBaseConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base.BaseURL != "https://example.com" || base.Theme.Font != "serif" {
+		t.Errorf("LoadBaseConfig() applied the overlay: %+v", base)
+	}
+}
+
+func TestMissingEnvironment(t *testing.T) {
+	writeFiles(t, map[string]string{"bazel.toml": "title = \"Blog\"\n"})
+	withEnv(t, "production")
+
+	_, err := LoadConfig()
+	if err == nil || err.Error() != `no config for environment "production", expected bazel.production.toml` {
+		t.Errorf("LoadConfig() error = %v", err)
+	}
+}
+
+func TestEnvPath(t *testing.T) {
+	old := Path
+	t.Cleanup(func() { Path = old })
+
+	for path, want := range map[string]string{
+		"bazel.toml":       "bazel.staging.toml",
+		"config/site.toml": "config/site.staging.toml",
+		"/etc/blog/x.toml": "/etc/blog/x.staging.toml",
+	} {
+		Path = path
+		if got := EnvPath("staging"); got != want {
+			t.Errorf("EnvPath with %s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestDirectories(t *testing.T) {
+	var defaults Config
+	custom := Config{OutputDir: "dist/", ContentDir: "content", StaticDir: "./assets"}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"default output", defaults.OutputPath(), "public"},
+		{"default posts", defaults.PostsPath(), "posts"},
+		{"default pages", defaults.PagesPath(), "pages"},
+		{"default static", defaults.StaticPath(), "static"},
+		{"output", custom.OutputPath(), "dist"},
+		{"posts", custom.PostsPath(), "content/posts"},
+		{"pages", custom.PagesPath(), "content/pages"},
+		{"static", custom.StaticPath(), "assets"},
+	}
+	for _, tt := range tests {
+		if got := strings.ReplaceAll(tt.got, string(os.PathSeparator), "/"); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateOutputDir(t *testing.T) {
+	tests := []struct {
+		config string
+		err    string // Empty when the output directory is accepted
+	}{
+		{config: ""},
+		{config: "output_dir = \"dist\""},
+		{config: "output_dir = \"build/site\"\ncontent_dir = \"content\""},
+		{config: "output_dir = \".\"", err: "site directory"},
+		{config: "output_dir = \"..\"", err: "site directory"},
+		{config: "output_dir = \"content\"\ncontent_dir = \"content/blog\"", err: "content_dir"},
+		{config: "output_dir = \"posts\"", err: "posts"},
+		{config: "output_dir = \"static\"", err: "static_dir"},
+		{config: "output_dir = \"themes\"", err: "themes"},
+	}
+	for _, tt := range tests {
+		writeFiles(t, map[string]string{"bazel.toml": "title = \"Blog\"\n" + tt.config + "\n"})
+
+		_, err := LoadConfig()
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%q: LoadConfig() error = %v", tt.config, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%q: LoadConfig() error = %v, want it to mention %s", tt.config, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// allowedValues restricts settings that only take one of a few values
+var allowedValues = map[string][]string{
+	"theme.color_scheme": ColorSchemes,
+	"theme.font":         Fonts,
+}
+
+// Get returns a setting by its dotted bazel.toml key, such as "title" or
+// "theme.font". Lists are joined with commas and sections are returned as TOML.
+func (c *Config) Get(key string) (string, error) {
+	value, err := c.lookup(key)
+	if err != nil {
+		return "", err
+	}
+	if !value.IsValid() {
+		return "", fmt.Errorf("%s is not set", key)
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Int:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Ptr:
+		if value.IsNil() {
+			return "", nil
+		}
+		return strconv.FormatBool(value.Elem().Bool()), nil
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.String {
+			return strings.Join(value.Interface().([]string), ", "), nil
+		}
+	}
+
+	// Only tables encode on their own, so arrays of tables are wrapped in one
+	section := value.Interface()
+	if value.Kind() == reflect.Slice {
+		section = map[string]interface{}{key[strings.LastIndex(key, ".")+1:]: section}
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(section); err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Set changes a setting by its dotted bazel.toml key, parsing value for the
+// setting's type. Lists take comma separated values, and an empty value
+// clears optional settings and removes map entries such as "socials.x".
+func (c *Config) Set(key, value string) error {
+	if allowed, ok := allowedValues[key]; ok && !contains(allowed, value) {
+		return fmt.Errorf("invalid value %q for %s, expected one of: %s", value, key, strings.Join(allowed, ", "))
+	}
+
+	// Map entries are not addressable and are stored instead of assigned
+	parent, name := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		parent, name = key[:i], key[i+1:]
+	}
+	if parent != "" {
+		if m, err := c.lookup(parent); err == nil && m.Kind() == reflect.Map {
+			if m.Type().Elem().Kind() != reflect.String {
+				return fmt.Errorf("%s cannot be set from the command line, edit bazel.toml instead", key)
+			}
+			if m.IsNil() {
+				m.Set(reflect.MakeMap(m.Type()))
+			}
+			if value == "" {
+				m.SetMapIndex(reflect.ValueOf(name), reflect.Value{})
+			} else {
+				m.SetMapIndex(reflect.ValueOf(name), reflect.ValueOf(value))
+			}
+			return nil
+		}
+	}
+
+	field, err := c.lookup(key)
+	if err != nil {
+		return err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid number %q for %s", value, key)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q for %s", value, key)
+		}
+		field.SetBool(b)
+	case reflect.Ptr:
+		if value == "" {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q for %s", value, key)
+		}
+		field.Set(reflect.ValueOf(&b))
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("%s cannot be set from the command line, edit bazel.toml instead", key)
+		}
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("%s is a section, set one of its keys such as %s.<key>", key, key)
+	}
+	return nil
+}
+
+// lookup finds the field for a dotted key by its toml tags. Map entries are
+// returned as their value, or the zero Value when the entry is missing.
+func (c *Config) lookup(key string) (reflect.Value, error) {
+	value := reflect.ValueOf(c).Elem()
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		switch value.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(value, part)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("unknown setting %q", strings.Join(parts[:i+1], "."))
+			}
+			value = field
+		case reflect.Map:
+			if i != len(parts)-1 {
+				return reflect.Value{}, fmt.Errorf("unknown setting %q", key)
+			}
+			return value.MapIndex(reflect.ValueOf(part)), nil
+		default:
+			return reflect.Value{}, fmt.Errorf("unknown setting %q", strings.Join(parts[:i+1], "."))
+		}
+	}
+	return value, nil
+}
+
+// Keys returns the dotted key of every setting, with the entries of maps
+// such as socials, for shell completion
+func (c *Config) Keys() []string {
+	keys, maps := c.settings()
+	for _, key := range maps {
+		m, _ := c.lookup(key)
+		for _, entry := range m.MapKeys() {
+			keys = append(keys, key+"."+entry.String())
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// settings returns the dotted keys of the settings, and separately those of
+// string maps such as socials, whose entries are settings of their own
+func (c *Config) settings() (keys, maps []string) {
+	var walk func(prefix string, value reflect.Value)
+	walk = func(prefix string, value reflect.Value) {
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("toml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			field := value.Field(i)
+			switch {
+			case field.Kind() == reflect.Struct:
+				walk(prefix+name+".", field)
+			case field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.String:
+				maps = append(maps, prefix+name)
+			default:
+				keys = append(keys, prefix+name)
+			}
+		}
+	}
+	walk("", reflect.ValueOf(c).Elem())
+	return keys, maps
+}
+
+// fieldByTag returns the field of a struct whose toml tag names key
+func fieldByTag(value reflect.Value, key string) (reflect.Value, bool) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("toml"), ",")[0]
+		if name == key {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
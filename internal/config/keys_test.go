@@ -0,0 +1,109 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func testConfig() *Config {
+	cfg := DefaultConfig
+	cfg.Socials = map[string]string{"github": "https://github.com/example"}
+	cfg.Menu = map[string][]MenuEntry{"main": {{Name: "Tags", URL: "/tags/"}}}
+	return &cfg
+}
+
+func TestGet(t *testing.T) {
+	cfg := testConfig()
+	cfg.Robots.Disallow = []string{"/a/", "/b/"}
+	yes := true
+	cfg.Markdown.Unsafe = &yes
+
+	tests := []struct {
+		key, want string
+	}{
+		{"title", cfg.Title},
+		{"theme.font", cfg.Theme.Font},
+		{"posts_per_page", "0"},
+		{"toc", "false"},
+		{"markdown.unsafe", "true"},
+		{"markdown.hard_wraps", ""},
+		{"robots.disallow", "/a/, /b/"},
+		{"socials.github", "https://github.com/example"},
+		{"menu.main", "[[main]]\n  name = \"Tags\"\n  url = \"/tags/\""},
+	}
+	for _, tt := range tests {
+		got, err := cfg.Get(tt.key)
+		if err != nil || got != tt.want {
+			t.Errorf("Get(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+		}
+	}
+
+	for key, want := range map[string]string{
+		"nope":           `unknown setting "nope"`,
+		"theme.nope":     `unknown setting "theme.nope"`,
+		"title.sub":      `unknown setting "title.sub"`,
+		"socials.twitch": "socials.twitch is not set",
+	} {
+		if _, err := cfg.Get(key); err == nil || err.Error() != want {
+			t.Errorf("Get(%q) error = %v, want %q", key, err, want)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		key, value string
+		check      func(*Config) bool
+	}{
+		{"title", "New Title", func(c *Config) bool { return c.Title == "New Title" }},
+		{"posts_per_page", "5", func(c *Config) bool { return c.PostsPerPage == 5 }},
+		{"toc", "true", func(c *Config) bool { return c.TOC }},
+		{"markdown.unsafe", "false", func(c *Config) bool { return c.Markdown.Unsafe != nil && !*c.Markdown.Unsafe }},
+		{"markdown.unsafe", "", func(c *Config) bool { return c.Markdown.Unsafe == nil }},
+		{"robots.allow", " /a/, ,/b/ ", func(c *Config) bool { return strings.Join(c.Robots.Allow, "|") == "/a/|/b/" }},
+		{"socials.mastodon", "https://example.social/@me", func(c *Config) bool { return c.Socials["mastodon"] == "https://example.social/@me" }},
+		{"socials.github", "", func(c *Config) bool { _, ok := c.Socials["github"]; return !ok }},
+		{"theme.color_scheme", "nord", func(c *Config) bool { return c.Theme.ColorScheme == "nord" }},
+	}
+	for _, tt := range tests {
+		cfg := testConfig()
+		if err := cfg.Set(tt.key, tt.value); err != nil {
+			t.Errorf("Set(%q, %q) = %v", tt.key, tt.value, err)
+		} else if !tt.check(cfg) {
+			t.Errorf("Set(%q, %q) did not change the setting", tt.key, tt.value)
+		}
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	tests := []struct {
+		key, value, want string
+	}{
+		{"posts_per_page", "many", `invalid number "many" for posts_per_page`},
+		{"toc", "maybe", `invalid boolean "maybe" for toc`},
+		{"theme.color_scheme", "neon", `invalid value "neon" for theme.color_scheme`},
+		{"theme", "x", "theme is a section, set one of its keys such as theme.<key>"},
+		{"menu.main", "x", "menu.main cannot be set from the command line, edit bazel.toml instead"},
+		{"bogus", "x", `unknown setting "bogus"`},
+	}
+	for _, tt := range tests {
+		cfg := testConfig()
+		if err := cfg.Set(tt.key, tt.value); err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("Set(%q, %q) error = %v, want %q", tt.key, tt.value, err, tt.want)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys := testConfig().Keys()
+	for _, want := range []string{"title", "theme.font", "markdown.math", "socials.github", "menu"} {
+		if !contains(keys, want) {
+			t.Errorf("Keys() is missing %q", want)
+		}
+	}
+	for _, key := range keys {
+		if _, err := testConfig().Get(key); err != nil && !strings.HasSuffix(err.Error(), "is not set") {
+			t.Errorf("Get(%q) for a listed key: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Overrides are key=value settings from the --set flag. They are applied
+// after the environment variables, so they win over every other source.
+var Overrides []string
+
+// envVarPrefix starts the environment variables that override settings
+const envVarPrefix = "BAZEL_"
+
+// EnvVar returns the environment variable that overrides a setting, such as
+// BAZEL_THEME_COLOR_SCHEME for theme.color_scheme
+func EnvVar(key string) string {
+	return envVarPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// recordSources marks the settings defined in a config file as coming from it
+func (c *Config) recordSources(sources map[string]string, meta toml.MetaData, path string) {
+	for _, key := range c.Keys() {
+		if meta.IsDefined(strings.Split(key, ".")...) {
+			sources[key] = path
+		}
+	}
+}
+
+// applyEnvVars sets every setting that has a BAZEL_ environment variable.
+// Entries of maps such as socials are added by their variable too, so
+// BAZEL_SOCIALS_MASTODON sets socials.mastodon.
+func (c *Config) applyEnvVars(sources map[string]string) error {
+	keys, maps := c.settings()
+	for _, key := range maps {
+		prefix := EnvVar(key) + "_"
+		for _, env := range os.Environ() {
+			name, _, _ := strings.Cut(env, "=")
+			if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+				keys = append(keys, key+"."+strings.ToLower(strings.TrimPrefix(name, prefix)))
+			}
+		}
+	}
+
+	for _, key := range keys {
+		value, ok := os.LookupEnv(EnvVar(key))
+		if !ok {
+			continue
+		}
+		if err := c.Set(key, value); err != nil {
+			return fmt.Errorf("%s: %w", EnvVar(key), err)
+		}
+		sources[key] = EnvVar(key)
+	}
+	return nil
+}
+
+// applyOverrides sets the key=value pairs of Overrides
+func (c *Config) applyOverrides(sources map[string]string) error {
+	for _, override := range Overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return fmt.Errorf("invalid --set %q, expected key=value", override)
+		}
+		key = strings.TrimSpace(key)
+		if err := c.Set(key, value); err != nil {
+			return fmt.Errorf("--set %s: %w", key, err)
+		}
+		sources[key] = "--set"
+	}
+	return nil
+}
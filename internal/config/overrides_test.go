@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestApplyEnvVars(t *testing.T) {
+	t.Setenv("BAZEL_TITLE", "From Env")
+	t.Setenv("BAZEL_THEME_COLOR_SCHEME", "nord")
+	t.Setenv("BAZEL_MARKDOWN_MATH", "true")
+	t.Setenv("BAZEL_SOCIALS_MASTODON", "https://example.social/@me")
+
+	cfg := DefaultConfig
+	cfg.Socials = make(map[string]string)
+	sources := make(map[string]string)
+	if err := cfg.applyEnvVars(sources); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"title":              "From Env",
+		"theme.color_scheme": "nord",
+		"markdown.math":      "true",
+		"socials.mastodon":   "https://example.social/@me",
+	}
+	for key, value := range want {
+		if got, _ := cfg.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+		if sources[key] != EnvVar(key) {
+			t.Errorf("source of %s = %q, want %q", key, sources[key], EnvVar(key))
+		}
+	}
+}
+
+func TestApplyEnvVarsInvalid(t *testing.T) {
+	t.Setenv("BAZEL_POSTS_PER_PAGE", "many")
+
+	cfg := DefaultConfig
+	err := cfg.applyEnvVars(make(map[string]string))
+	if err == nil || !strings.Contains(err.Error(), "BAZEL_POSTS_PER_PAGE") {
+		t.Errorf("error = %v, want one naming BAZEL_POSTS_PER_PAGE", err)
+	}
+}
+
+func TestApplyOverridesInvalid(t *testing.T) {
+	for _, override := range []string{"title", "nope=1", "toc=sure"} {
+		t.Run(override, func(t *testing.T) {
+			withGlobals(t, "", []string{override})
+			cfg := DefaultConfig
+			if err := cfg.applyOverrides(make(map[string]string)); err == nil {
+				t.Errorf("--set %s was accepted", override)
+			}
+		})
+	}
+}
+
+// withGlobals sets the environment and --set overrides for one test
+func withGlobals(t *testing.T, env string, overrides []string) {
+	t.Helper()
+	oldEnv, oldOverrides := Env, Overrides
+	Env, Overrides = env, overrides
+	t.Cleanup(func() {
+		Env, Overrides = oldEnv, oldOverrides
+	})
+}
+
+func TestLoadResolvedConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"bazel.toml": `title = "Base"
+description = "Base"
+base_url = "https://base.example.com"
+posts_per_page = 3
+
+[theme]
+font = "serif"
+`,
+		"bazel.staging.toml": `title = "Overlay"
+description = "Overlay"
+base_url = "https://staging.example.com"
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("BAZEL_TITLE", "Env")
+	t.Setenv("BAZEL_DESCRIPTION", "Env")
+	withGlobals(t, "staging", []string{"title=Set"})
+
+	cfg, sources, err := LoadResolvedConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key, value, source string
+	}{
+		{"title", "Set", "--set"},
+		{"description", "Env", "BAZEL_DESCRIPTION"},
+		{"base_url", "https://staging.example.com", "bazel.staging.toml"},
+		{"posts_per_page", "3", "bazel.toml"},
+		{"theme.font", "serif", "bazel.toml"},
+		{"theme.color_scheme", "", "default"},
+	}
+	for _, tt := range tests {
+		got, err := cfg.Get(tt.key)
+		if err != nil {
+			t.Errorf("Get(%q): %v", tt.key, err)
+		}
+		if got != tt.value {
+			t.Errorf("%s = %q, want %q", tt.key, got, tt.value)
+		}
+		if sources[tt.key] != tt.source {
+			t.Errorf("source of %s = %q, want %q", tt.key, sources[tt.key], tt.source)
+		}
+	}
+
+	for _, key := range cfg.Keys() {
+		if sources[key] == "" {
+			t.Errorf("%s has no source", key)
+		}
+	}
+}
+
+func TestLoadResolvedConfigMissingEnv(t *testing.T) {
+	t.Chdir(t.TempDir())
+	withGlobals(t, "production", nil)
+
+	if _, _, err := LoadResolvedConfig(); err == nil || !strings.Contains(err.Error(), "bazel.production.toml") {
+		t.Errorf("error = %v, want one naming bazel.production.toml", err)
+	}
+}
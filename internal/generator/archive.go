@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+)
+
+// Paginator describes one page of a paginated post listing
+type Paginator struct {
+	PageNumber int
+	TotalPages int
+	PrevURL    string // Relative to public/, empty on the first page
+	NextURL    string // Relative to public/, empty on the last page
+}
+
+// Archive lists the posts published in a year, or in a month of a year
+type Archive struct {
+	Title  string // "2025" or "July 2025"
+	Year   int
+	Month  time.Month // Zero for a year archive
+	URL    string     // e.g. "archive/2025/" or "archive/2025/07/"
+	Posts  []Post
+	Months []*Archive // Month archives of a year, newest first
+}
+
+// indexPageURL returns the URL of the nth page of the home page listing
+func indexPageURL(n int) string {
+	if n <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("page/%d/", n)
+}
+
+// generateIndex writes the home page, split into pages of posts_per_page
+// posts with previous/next links when pagination is enabled.
+func (s *Site) generateIndex() error {
+	perPage := s.Config.PostsPerPage
+	if perPage <= 0 || perPage > len(s.Posts) {
+		perPage = len(s.Posts)
+	}
+
+	totalPages := 1
+	if perPage > 0 {
+		totalPages = (len(s.Posts) + perPage - 1) / perPage
+	}
+
+	for n := 1; n <= totalPages; n++ {
+		start := (n - 1) * perPage
+		end := min(start+perPage, len(s.Posts))
+
+		paginator := &Paginator{PageNumber: n, TotalPages: totalPages}
+		if n > 1 {
+			paginator.PrevURL = indexPageURL(n - 1)
+			if paginator.PrevURL == "" {
+				paginator.PrevURL = "index.html"
+			}
+		}
+		if n < totalPages {
+			paginator.NextURL = indexPageURL(n + 1)
+		}
+
+		url := indexPageURL(n)
+		err := s.renderLayout("index", url+"index.html", LayoutData{
+			Title:       s.Config.Title,
+			Description: s.Config.Description,
+			URL:         url,
+			Permalink:   s.absURL(url),
+			Posts:       s.Posts[start:end:end],
+			Paginator:   paginator,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildArchives groups posts by year and month, newest first
+func (s *Site) buildArchives() {
+	s.Archives = nil
+
+	var year, month *Archive
+	for _, post := range s.Posts {
+		y, m := post.Date.Year(), post.Date.Month()
+		if year == nil || year.Year != y {
+			year = &Archive{
+				Title: fmt.Sprint(y),
+				Year:  y,
+				URL:   fmt.Sprintf("archive/%d/", y),
+			}
+			s.Archives = append(s.Archives, year)
+			month = nil
+		}
+		if month == nil || month.Month != m {
+			month = &Archive{
+				Title: fmt.Sprintf("%s %d", m, y),
+				Year:  y,
+				Month: m,
+				URL:   fmt.Sprintf("archive/%d/%02d/", y, m),
+			}
+			year.Months = append(year.Months, month)
+		}
+		year.Posts = append(year.Posts, post)
+		month.Posts = append(month.Posts, post)
+	}
+}
+
+// generateArchives writes the archive overview and a page for every year
+// and month that has posts.
+func (s *Site) generateArchives() error {
+	if len(s.Archives) == 0 {
+		return nil
+	}
+
+	err := s.renderLayout("archive", "archive/index.html", LayoutData{
+		Title:       "Archive",
+		Description: "All posts by date - " + s.Config.Description,
+		URL:         "archive/",
+		Permalink:   s.absURL("archive/"),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, year := range s.Archives {
+		for _, archive := range append([]*Archive{year}, year.Months...) {
+			err := s.renderLayout("archive", archive.URL+"index.html", LayoutData{
+				Title:       archive.Title,
+				Description: fmt.Sprintf("Posts from %s - %s", archive.Title, s.Config.Description),
+				URL:         archive.URL,
+				Permalink:   s.absURL(archive.URL),
+				Posts:       archive.Posts,
+				Archive:     archive,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
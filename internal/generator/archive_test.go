@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"fmt"
+	"testing"
+)
+
+// archiveSite has five posts over two years, one per month of 2024 plus
+// two in December 2023
+func archiveSite(perPage int) map[string]string {
+	files := map[string]string{
+		"bazel.toml": fmt.Sprintf("posts_per_page = %d\n", perPage),
+	}
+	dates := []string{"2023-12-05", "2023-12-20", "2024-01-10", "2024-02-10", "2024-03-10"}
+	for i, date := range dates {
+		files[fmt.Sprintf("posts/post%d.md", i+1)] = markdownFile(fmt.Sprintf("title: Post %d\ndate: %s", i+1, date), "body")
+	}
+	return files
+}
+
+func TestPagination(t *testing.T) {
+	newTestSite(t, archiveSite(2))
+	buildTestSite(t)
+
+	pages := []struct {
+		file     string
+		want     []string
+		unwanted []string
+	}{
+		{"index.html", []string{"Post 5", "Post 4", "Page 1 of 3", `href="./page/2/"`}, []string{"Post 3", "Newer posts"}},
+		{"page/2/index.html", []string{"Post 3", "Post 2", "Page 2 of 3", `href="../../index.html"`, `href="../../page/3/"`}, []string{"Post 5", "Post 1"}},
+		{"page/3/index.html", []string{"Post 1", "Page 3 of 3", `href="../../page/2/"`}, []string{"Post 2", "Older posts"}},
+	}
+	for _, page := range pages {
+		assertContains(t, page.file, page.want...)
+		assertNotContains(t, page.file, page.unwanted...)
+	}
+	if hasOutput("page/4/index.html") || hasOutput("page/1/index.html") {
+		t.Error("pages beyond the posts, or a duplicate first page, were generated")
+	}
+}
+
+func TestNoPagination(t *testing.T) {
+	newTestSite(t, archiveSite(0))
+	buildTestSite(t)
+
+	assertContains(t, "index.html", "Post 1", "Post 5")
+	assertNotContains(t, "index.html", "Page 1 of")
+	if hasOutput("page/2/index.html") {
+		t.Error("page/2/ was generated without posts_per_page")
+	}
+}
+
+func TestArchives(t *testing.T) {
+	newTestSite(t, archiveSite(0))
+	buildTestSite(t)
+
+	assertContains(t, "archive/index.html", "2024", "2023", "archive/2024/03/", "archive/2023/12/")
+	assertContains(t, "archive/2024/index.html", "Post 3", "Post 4", "Post 5")
+	assertNotContains(t, "archive/2024/index.html", "Post 1", "Post 2")
+	assertContains(t, "archive/2023/12/index.html", "December 2023", "Post 1", "Post 2")
+	assertContains(t, "archive/2024/02/index.html", "Post 4")
+	assertNotContains(t, "archive/2024/02/index.html", "Post 3", "Post 5")
+	if hasOutput("archive/2024/04/index.html") {
+		t.Error("an archive without posts was generated")
+	}
+}
+
+func TestBuildArchives(t *testing.T) {
+	s := &Site{}
+	for _, date := range []string{"2024-03-10", "2024-03-01", "2024-01-10", "2023-12-20"} {
+		s.Posts = append(s.Posts, Post{Title: date, Date: mustDate(t, date)})
+	}
+	s.buildArchives()
+
+	var got []string
+	for _, year := range s.Archives {
+		got = append(got, fmt.Sprintf("%s:%d", year.URL, len(year.Posts)))
+		for _, month := range year.Months {
+			got = append(got, fmt.Sprintf("%s:%d", month.URL, len(month.Posts)))
+		}
+	}
+	want := fmt.Sprint([]string{"archive/2024/:3", "archive/2024/03/:2", "archive/2024/01/:1", "archive/2023/:1", "archive/2023/12/:1"})
+	if fmt.Sprint(got) != want {
+		t.Errorf("archives = %v, want %v", got, want)
+	}
+}
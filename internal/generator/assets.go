@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// bundleIndex is the post inside a page bundle, posts/<name>/index.md
+const bundleIndex = "index.md"
+
+// bundleIndexFile returns the index.md of a page bundle directory
+func bundleIndexFile(dir string) (string, bool) {
+	index := filepath.Join(dir, bundleIndex)
+	if info, err := os.Stat(index); err == nil && !info.IsDir() {
+		return index, true
+	}
+	return "", false
+}
+
+// bundleAssetDir returns where a bundle's assets are copied, relative to
+// public/. Posts written to <path>/index.html keep their assets beside them;
+// posts written to <name>.html get a <name>/ directory next to the file.
+func bundleAssetDir(url string) string {
+	if url == "" || strings.HasSuffix(url, "/") {
+		return url
+	}
+	return strings.TrimSuffix(url, path.Ext(url)) + "/"
+}
+
+// bundleLinkBase is prepended to relative links in a bundle's content so
+// they resolve against the asset directory from the rendered post
+func bundleLinkBase(url string) string {
+	if url == "" || strings.HasSuffix(url, "/") {
+		return ""
+	}
+	return path.Base(bundleAssetDir(url)) + "/"
+}
+
+// bundleLinks rebases relative links to the files of a page bundle. Links
+// to anything else, such as ../pages/about.html, are left as written.
+type bundleLinks struct {
+	base  string          // From the rendered post to the copied assets
+	files map[string]bool // Slash separated paths relative to the bundle
+}
+
+// newBundleLinks lists the files of the bundle in dir, whose post is
+// rendered at url
+func newBundleLinks(dir, url string) *bundleLinks {
+	links := &bundleLinks{base: bundleLinkBase(url), files: make(map[string]bool)}
+	filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if rel, err := filepath.Rel(dir, p); err == nil && rel != bundleIndex {
+			links.files[filepath.ToSlash(rel)] = true
+		}
+		return nil
+	})
+	return links
+}
+
+// key identifies the rewriting for the markdown cache, so adding or
+// removing a bundle file renders the post again
+func (b *bundleLinks) key() string {
+	if b == nil {
+		return ""
+	}
+	files := make([]string, 0, len(b.files))
+	for file := range b.files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return hashStrings(append([]string{b.base}, files...)...)
+}
+
+// resolveImage sets the path and absolute URL of a post's cover image.
+// Relative images belong to the post's bundle, or to the site root for
+// posts that are single files.
+func (s *Site) resolveImage(post *Post) {
+	image := post.Image
+	switch {
+	case image == "":
+		return
+	case strings.Contains(image, "://") || strings.HasPrefix(image, "//"):
+		post.ImageURL = image
+		return
+	case strings.HasPrefix(image, "/"):
+		post.ImagePath = strings.TrimPrefix(image, "/")
+	case post.Bundle != "":
+		post.ImagePath = bundleAssetDir(post.URL) + strings.TrimPrefix(image, "./")
+	default:
+		post.ImagePath = strings.TrimPrefix(image, "./")
+	}
+	post.ImageURL = s.absURL(post.ImagePath)
+}
+
+// copyStatic copies the static/ directory into public/
+func (s *Site) copyStatic() error {
+	staticDir := s.Config.StaticPath()
+	if _, err := os.Stat(staticDir); os.IsNotExist(err) {
+		return nil
+	}
+	return s.copyDir(staticDir, "", nil)
+}
+
+// copyBundleAssets copies the files of every page bundle next to the post
+func (s *Site) copyBundleAssets() error {
+	for _, post := range s.Posts {
+		if post.Bundle == "" {
+			continue
+		}
+
+		err := s.copyDir(post.Bundle, bundleAssetDir(post.URL), func(rel string) bool {
+			return rel == bundleIndex
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyDir copies the files below src into dst, relative to public/,
+// skipping paths relative to src for which skip returns true
+func (s *Site) copyDir(src, dst string, skip func(rel string) bool) error {
+	return filepath.WalkDir(src, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if skip != nil && skip(rel) {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return s.writeOutput(path.Join(dst, rel), content)
+	})
+}
+
+// bundleLinksKey carries the *bundleLinks of a bundle post through a conversion
+var bundleLinksKey = parser.NewContextKey()
+
+// bundleLinkTransformer rewrites relative link and image destinations so
+// assets of a page bundle resolve from the post's rendered location
+type bundleLinkTransformer struct{}
+
+// Transform implements parser.ASTTransformer
+func (bundleLinkTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	links, _ := pc.Get(bundleLinksKey).(*bundleLinks)
+	if links == nil || links.base == "" {
+		return
+	}
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.Link:
+			node.Destination = links.rebase(node.Destination)
+		case *ast.Image:
+			node.Destination = links.rebase(node.Destination)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// rebase prefixes a relative link to a file of the bundle with the base,
+// leaving every other link untouched
+func (b *bundleLinks) rebase(dest []byte) []byte {
+	link := string(dest)
+	if b == nil || b.base == "" || link == "" || strings.HasPrefix(link, "/") ||
+		strings.HasPrefix(link, "#") || strings.HasPrefix(link, "?") || strings.Contains(link, ":") {
+		return dest
+	}
+
+	file := link
+	if i := strings.IndexAny(file, "?#"); i >= 0 {
+		file = file[:i]
+	}
+	if unescaped, err := url.PathUnescape(file); err == nil {
+		file = unescaped
+	}
+	if !b.files[path.Clean(file)] {
+		return dest
+	}
+	return []byte(b.base + strings.TrimPrefix(link, "./"))
+}
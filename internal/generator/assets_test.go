@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBundleAssetDir(t *testing.T) {
+	tests := []struct {
+		url, dir, base string
+	}{
+		{"posts/trip.html", "posts/trip/", "trip/"},
+		{"2024/01/trip/", "2024/01/trip/", ""},
+		{"trip.html", "trip/", "trip/"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := bundleAssetDir(tt.url); got != tt.dir {
+			t.Errorf("bundleAssetDir(%q) = %q, want %q", tt.url, got, tt.dir)
+		}
+		if got := bundleLinkBase(tt.url); got != tt.base {
+			t.Errorf("bundleLinkBase(%q) = %q, want %q", tt.url, got, tt.base)
+		}
+	}
+}
+
+func TestStaticFiles(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"static/robots-extra.txt": "extra",
+		"static/img/logo.svg":     "<svg/>",
+		"posts/hello.md":          markdownFile("title: Hello\ndate: 2024-01-02", "Hello"),
+	})
+	buildTestSite(t)
+
+	if got := readOutput(t, "img/logo.svg"); got != "<svg/>" {
+		t.Errorf("img/logo.svg = %q, want the static file", got)
+	}
+	if !hasOutput("robots-extra.txt") {
+		t.Error("static/robots-extra.txt was not copied")
+	}
+}
+
+func TestPageBundles(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		post      string
+		asset     string
+		imageLink string
+		mapLink   string
+	}{
+		{
+			name:      "default permalink",
+			post:      "posts/trip.html",
+			asset:     "posts/trip/photo.jpg",
+			imageLink: `src="trip/photo.jpg"`,
+			mapLink:   `href="trip/maps/a.gpx#start"`,
+		},
+		{
+			name:      "directory permalink",
+			config:    "permalink = \"/:slug/\"\n",
+			post:      "trip/index.html",
+			asset:     "trip/photo.jpg",
+			imageLink: `src="photo.jpg"`,
+			mapLink:   `href="./maps/a.gpx#start"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestSite(t, map[string]string{
+				"bazel.toml":            "title = \"Bundles\"\n" + tt.config,
+				"posts/trip/index.md":   markdownFile("title: Trip\ndate: 2024-01-02", "![photo](photo.jpg) [map](./maps/a.gpx#start) [about](../pages/about.html) [web](https://example.com/x.jpg) [top](#top)"),
+				"posts/trip/photo.jpg":  "jpeg",
+				"posts/trip/maps/a.gpx": "gpx",
+				"posts/hello.md":        markdownFile("title: Hello\ndate: 2024-01-03", "Hello"),
+			})
+			buildTestSite(t)
+
+			assertContains(t, tt.post, "<h1>Trip</h1>", tt.imageLink, tt.mapLink,
+				`href="../pages/about.html"`, `href="https://example.com/x.jpg"`, `href="#top"`)
+			if got := readOutput(t, tt.asset); got != "jpeg" {
+				t.Errorf("%s = %q, want the bundle asset", tt.asset, got)
+			}
+			if !hasOutput(filepath.Join(filepath.Dir(tt.asset), "maps", "a.gpx")) {
+				t.Error("nested bundle asset was not copied")
+			}
+			if hasOutput(filepath.Join(filepath.Dir(tt.asset), "index.md")) {
+				t.Error("bundle index.md was copied as an asset")
+			}
+		})
+	}
+}
+
+func TestPublishBundle(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/drafts/trip/index.md":  markdownFile("title: Trip\ndraft: true", "Trip"),
+		"posts/drafts/trip/photo.jpg": "jpeg",
+	})
+
+	drafts, err := ListDraftPosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 1 || drafts[0] != "trip" {
+		t.Fatalf("ListDraftPosts() = %q, want the trip bundle", drafts)
+	}
+
+	if err := PublishPost("trip"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join("posts", "trip", "photo.jpg")); err != nil {
+		t.Errorf("published bundle lost its assets: %v", err)
+	}
+	if isDraftFile(filepath.Join("posts", "trip", "index.md")) {
+		t.Error("published bundle is still marked as a draft")
+	}
+
+	if err := UnpublishPost("trip"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join("posts", "drafts", "trip", "photo.jpg")); err != nil {
+		t.Errorf("unpublished bundle lost its assets: %v", err)
+	}
+}
+
+func TestPostFile(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/hello.md":             markdownFile("title: Hello\ndate: 2024-01-02", "Hello"),
+		"posts/trip/index.md":        markdownFile("title: Trip\ndate: 2024-01-03", "Trip"),
+		"posts/drafts/idea.md":       markdownFile("title: Idea\ndraft: true", "Idea"),
+		"posts/drafts/hike/index.md": markdownFile("title: Hike\ndraft: true", "Hike"),
+	})
+
+	for name, want := range map[string]string{
+		"hello": filepath.Join("posts", "hello.md"),
+		"trip":  filepath.Join("posts", "trip", "index.md"),
+		"idea":  filepath.Join("posts", "drafts", "idea.md"),
+		"hike":  filepath.Join("posts", "drafts", "hike", "index.md"),
+	} {
+		if got, err := PostFile(name); err != nil || got != want {
+			t.Errorf("PostFile(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := PostFile("missing"); err == nil {
+		t.Error("PostFile of a missing post succeeded")
+	}
+}
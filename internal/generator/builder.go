@@ -2,6 +2,7 @@ package generator
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"html/template"
 	"io/ioutil"
@@ -9,90 +10,228 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/Masterminds/sprig/v3"
-	"github.com/adrg/frontmatter"
 	"github.com/araddon/dateparse"
 	"github.com/yourusername/bazel_blog/internal/config"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
 	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
 )
 
 type Post struct {
-	Title    string
-	Date     time.Time
-	Content  string
-	Filename string
-	URL      string
+	Title     string
+	Date      time.Time
+	Content   string
+	Filename  string
+	Slug      string
+	URL       string // Relative to public/, set from the permalink pattern
+	Permalink string // Absolute URL including base_url
+	Draft     bool
+	Bundle    string // Source directory when the post is a page bundle
+	TOC       string // Table of contents, empty unless enabled
+
+	Summary     string // Plain text from the description, <!--more--> or first words
+	WordCount   int
+	ReadingTime int // Minutes
+
+	Description string
+	Author      string    // Falls back to the site author
+	Updated     time.Time // Zero unless the post was revised
+	Lang        string
+	Image       string // Cover image as written in the frontmatter
+	ImagePath   string // Cover image relative to public/, empty for external images
+	ImageURL    string // Absolute cover image URL
+	Params      map[string]interface{}
+
+	sourceHash string
+	publishAt  time.Time // When the post goes live, from publish_date or the date
+
+	Tags       []string
+	Categories []string
+	Aliases    []string // Old paths that redirect to this post
+
+	Series     string     // Name of the series the post is a part of
+	SeriesPart int        // Position from the series_part frontmatter, zero when unset
+	SeriesNav  *SeriesNav // The post's place in its series, nil outside a series
 }
 
 type Page struct {
 	Title    string
 	Content  string
-	Filename string
+	Filename string // Relative to the pages directory
 	URL      string
+	Draft    bool
+	LastMod  time.Time // Modification time of the source file
+	Weight   int       // Position in menus and sections, lower first
+	Menu     string    // Menu the page is listed in, "none" for no menu
+	Section  string    // Directory under pages/, empty for top-level pages
+
+	path       string // Source file
+	sourceHash string
 }
 
 // PostMatter represents the frontmatter structure for posts
 type PostMatter struct {
-	Title string `yaml:"title"`
-	Date  string `yaml:"date"`
+	Title       string     `yaml:"title" toml:"title" json:"title"`
+	Description string     `yaml:"description" toml:"description" json:"description"`
+	Date        DateString `yaml:"date" toml:"date" json:"date"`
+	Time        string     `yaml:"time" toml:"time" json:"time"`
+	Updated     DateString `yaml:"updated" toml:"updated" json:"updated"`
+	PublishDate DateString `yaml:"publish_date" toml:"publish_date" json:"publish_date"`
+	ExpiryDate  DateString `yaml:"expiry_date" toml:"expiry_date" json:"expiry_date"`
+	Author      string     `yaml:"author" toml:"author" json:"author"`
+	Image       string     `yaml:"image" toml:"image" json:"image"`
+	Lang        string     `yaml:"lang" toml:"lang" json:"lang"`
+	Slug        string     `yaml:"slug" toml:"slug" json:"slug"`
+	Draft       bool       `yaml:"draft" toml:"draft" json:"draft"`
+	Tags        StringList `yaml:"tags" toml:"tags" json:"tags"`
+	Categories  StringList `yaml:"categories" toml:"categories" json:"categories"`
+	Aliases     StringList `yaml:"aliases" toml:"aliases" json:"aliases"`
+	Series      string     `yaml:"series" toml:"series" json:"series"`
+	SeriesPart  int        `yaml:"series_part" toml:"series_part" json:"series_part"`
+	TOC         *bool      `yaml:"toc" toml:"toc" json:"toc"`
+
+	Params map[string]interface{} `yaml:"params" toml:"params" json:"params"`
 }
 
 // PageMatter represents the frontmatter structure for pages
 type PageMatter struct {
-	Title string `yaml:"title"`
+	Title string     `yaml:"title" toml:"title" json:"title"`
+	Date  DateString `yaml:"date" toml:"date" json:"date"` // Written by `bazel page`, not shown
+	Draft bool       `yaml:"draft" toml:"draft" json:"draft"`
+
+	// Weight orders pages in menus and sections; unweighted pages come last
+	Weight int `yaml:"weight" toml:"weight" json:"weight"`
+	// Menu lists the page in another menu than "main", or in none with "none"
+	Menu string `yaml:"menu" toml:"menu" json:"menu"`
+}
+
+// BuildOptions controls which content is included in a build
+type BuildOptions struct {
+	Drafts bool // Include draft posts and pages
+	Clean  bool // Discard the previous output and build cache
+	Strict bool // Fail on malformed frontmatter instead of falling back
+	Future bool // Include posts dated or scheduled in the future
 }
 
 type Site struct {
-	Config *config.Config
-	Posts  []Post
-	Pages  []Page
+	Config  *config.Config
+	Options BuildOptions
+	Posts   []Post
+	Pages   []Page
+
+	// Taxonomies groups posts by tags and categories, keyed by plural name
+	Taxonomies map[string]*Taxonomy
+
+	// Archives groups posts by year and month, newest first
+	Archives []*Archive
+
+	// Sections are the subdirectories of pages/, each with an index page
+	Sections []*Section
+
+	// Menus holds the navigation menus by name, in display order
+	Menus map[string][]MenuItem
+
+	layouts    map[string]*template.Template
+	layoutHash string
+	sitemap    []sitemapEntry
+
+	// now is the build time scheduled posts are compared against
+	now time.Time
+	// loc is the time zone of post dates that do not name one
+	loc *time.Location
+
+	// cache lets unchanged content skip rendering and writing
+	cache   *buildCache
+	siteKey string
+
+	shortcodes  *shortcodeSet
+	markdown    goldmark.Markdown
+	markdownKey string     // Hash of the settings that change rendered markdown
+	mu          sync.Mutex // Guards sitemap while pages render in parallel
 }
 
+// newSite returns a site ready to load content, with its markdown renderer
+// set up for the config
+func newSite(cfg *config.Config, opts BuildOptions) (*Site, error) {
+	loc, err := cfg.Location()
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	site := &Site{
+		Config:  cfg,
+		Options: opts,
+		Posts:   []Post{},
+		Pages:   []Page{},
+		now:     time.Now(),
+		loc:     loc,
+		cache:   loadCache(cfg.OutputPath()),
+	}
+	if err := site.loadShortcodes(); err != nil {
+		return nil, fmt.Errorf("failed to load shortcodes: %w", err)
+	}
+	site.markdown = newMarkdown(cfg, site.shortcodes)
+	site.computeMarkdownKey()
+	return site, nil
+}
+
+// BuildSite builds the site in the current directory for publishing
 func BuildSite() error {
+	return BuildSiteWithOptions(BuildOptions{})
+}
+
+// BuildSiteWithOptions builds the site in the current directory
+func BuildSiteWithOptions(opts BuildOptions) error {
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Create output directory structure
-	outputDir := "public"
-	if err := os.RemoveAll(outputDir); err != nil {
-		return fmt.Errorf("failed to remove output directory: %w", err)
+	// Create output directory structure. Files from the previous build are
+	// kept so unchanged ones are not rewritten, unless a clean build is asked for.
+	outputDir := cfg.OutputPath()
+	if opts.Clean {
+		if err := os.RemoveAll(outputDir); err != nil {
+			return fmt.Errorf("failed to remove output directory: %w", err)
+		}
+		if err := os.RemoveAll(cacheDir); err != nil {
+			return fmt.Errorf("failed to remove build cache: %w", err)
+		}
 	}
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Create subdirectories
-	if err := os.MkdirAll(filepath.Join(outputDir, "posts"), 0755); err != nil {
-		return fmt.Errorf("failed to create posts directory: %w", err)
-	}
-	if err := os.MkdirAll(filepath.Join(outputDir, "pages"), 0755); err != nil {
-		return fmt.Errorf("failed to create pages directory: %w", err)
-	}
-
 	// Build site structure
-	site := &Site{
-		Config: cfg,
-		Posts:  []Post{},
-		Pages:  []Page{},
+	site, err := newSite(cfg, opts)
+	if err != nil {
+		return err
 	}
 
-	// Load posts
-	if err := site.loadPosts(); err != nil {
-		return fmt.Errorf("failed to load posts: %w", err)
+	// Load posts and pages, reporting the problems of every file at once
+	postsErr := site.loadPosts()
+	pagesErr := site.loadPages()
+	if err := joinDiagnostics(postsErr, pagesErr); err != nil {
+		return fmt.Errorf("failed to load content: %w", err)
 	}
 
-	// Load pages
-	if err := site.loadPages(); err != nil {
-		return fmt.Errorf("failed to load pages: %w", err)
+	// Group posts by tags and categories, and by date
+	site.buildTaxonomies()
+	site.buildSeries()
+	site.buildArchives()
+
+	// Load layouts from the theme, falling back to the built-in ones
+	if err := site.loadLayouts(); err != nil {
+		return fmt.Errorf("failed to load layouts: %w", err)
 	}
+	site.computeSiteKey()
 
 	// Generate CSS
 	if err := site.generateCSS(); err != nil {
@@ -114,158 +253,478 @@ func BuildSite() error {
 		return fmt.Errorf("failed to generate pages: %w", err)
 	}
 
-	// Generate RSS feed
-	if err := site.generateRSS(); err != nil {
-		return fmt.Errorf("failed to generate RSS feed: %w", err)
+	// Generate section index pages
+	if err := site.generateSections(); err != nil {
+		return fmt.Errorf("failed to generate sections: %w", err)
+	}
+
+	// Generate tag and category pages
+	if err := site.generateTaxonomies(); err != nil {
+		return fmt.Errorf("failed to generate taxonomies: %w", err)
+	}
+
+	// Generate year and month archives
+	if err := site.generateArchives(); err != nil {
+		return fmt.Errorf("failed to generate archives: %w", err)
+	}
+
+	// Generate RSS, Atom and JSON feeds
+	if err := site.generateFeeds(); err != nil {
+		return fmt.Errorf("failed to generate feeds: %w", err)
+	}
+
+	// Copy page bundle assets
+	if err := site.copyBundleAssets(); err != nil {
+		return fmt.Errorf("failed to copy bundle assets: %w", err)
+	}
+
+	// Generate sitemap.xml and robots.txt
+	if err := site.generateSitemap(); err != nil {
+		return fmt.Errorf("failed to generate sitemap: %w", err)
+	}
+	if err := site.generateRobots(); err != nil {
+		return fmt.Errorf("failed to generate robots.txt: %w", err)
+	}
+
+	// Generate redirects from post aliases, after every other output so an
+	// alias cannot silently replace one
+	if err := site.generateRedirects(); err != nil {
+		return fmt.Errorf("failed to generate redirects: %w", err)
+	}
+
+	// Copy the static/ directory last so it can replace generated files
+	// such as robots.txt
+	if err := site.copyStatic(); err != nil {
+		return fmt.Errorf("failed to copy static files: %w", err)
+	}
+
+	// Remove files the previous build wrote that are no longer generated
+	if err := site.removeStaleOutputs(); err != nil {
+		return fmt.Errorf("failed to remove stale output: %w", err)
+	}
+	if err := site.cache.save(); err != nil {
+		return fmt.Errorf("failed to save build cache: %w", err)
 	}
 
 	return nil
 }
 
-func (s *Site) loadPosts() error {
-	postsDir := "posts"
-	if _, err := os.Stat(postsDir); os.IsNotExist(err) {
-		return nil // No posts directory
+// contentDirs returns the directories content is loaded from, including
+// the drafts/ subdirectory when drafts are part of the build.
+func (s *Site) contentDirs(dir string) []string {
+	dirs := []string{dir}
+	if s.Options.Drafts {
+		dirs = append(dirs, filepath.Join(dir, draftsDirName))
 	}
+	return dirs
+}
 
-	files, err := ioutil.ReadDir(postsDir)
-	if err != nil {
-		return err
-	}
+// postSource is a markdown file in posts/, either on its own or as the
+// index.md of a page bundle
+type postSource struct {
+	path        string
+	filename    string // Relative to the posts directory
+	name        string // Filename without extension, or the bundle directory
+	bundle      string
+	info        os.FileInfo
+	isDraftsDir bool
+}
 
-	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".md") {
-			content, err := ioutil.ReadFile(filepath.Join(postsDir, file.Name()))
-			if err != nil {
-				continue
-			}
+// postSources lists the posts in posts/, and in posts/drafts/ when drafts
+// are part of the build
+func (s *Site) postSources() ([]postSource, error) {
+	var sources []postSource
+	for _, postsDir := range s.contentDirs(s.Config.PostsPath()) {
+		if _, err := os.Stat(postsDir); os.IsNotExist(err) {
+			continue // No posts directory
+		}
 
-			// Parse frontmatter and content using the frontmatter library
-			var matter PostMatter
-			rest, err := frontmatter.Parse(strings.NewReader(string(content)), &matter)
-			if err != nil {
-				// If frontmatter parsing fails, use defaults and clean filename
-				cleanTitle := strings.TrimSpace(strings.ReplaceAll(file.Name(), "_", " "))
-				matter.Title = strings.TrimSuffix(cleanTitle, ".md")
-				matter.Date = ""
-				rest = content
-			}
+		files, err := ioutil.ReadDir(postsDir)
+		if err != nil {
+			return nil, err
+		}
 
-			// Use title from frontmatter or fallback to cleaned filename
-			title := matter.Title
-			if title == "" {
-				cleanTitle := strings.TrimSpace(strings.ReplaceAll(file.Name(), "_", " "))
-				title = strings.TrimSuffix(cleanTitle, ".md")
+		isDraftsDir := filepath.Base(postsDir) == draftsDirName
+		for _, file := range files {
+			src := postSource{
+				path:        filepath.Join(postsDir, file.Name()),
+				filename:    file.Name(),
+				name:        strings.TrimSuffix(file.Name(), ".md"),
+				info:        file,
+				isDraftsDir: isDraftsDir,
 			}
 
-			// Parse date with flexible parsing
-			var postDate time.Time
-			if matter.Date != "" {
-				if parsedDate, err := dateparse.ParseAny(matter.Date); err == nil {
-					postDate = parsedDate
-				} else {
-					// Fallback to file modification time
-					postDate = file.ModTime()
+			// Page bundles keep a post's assets next to posts/<name>/index.md
+			if file.IsDir() {
+				index, ok := bundleIndexFile(src.path)
+				if !ok || file.Name() == draftsDirName {
+					continue
 				}
-			} else {
-				postDate = file.ModTime()
+				if src.info, err = os.Stat(index); err != nil {
+					continue
+				}
+				src.bundle, src.path = src.path, index
+				src.filename = file.Name() + "/" + bundleIndex
+			} else if !strings.HasSuffix(file.Name(), ".md") {
+				continue
 			}
 
-			// Convert markdown to HTML using enhanced Goldmark
-			htmlContent := s.markdownToHTML(strings.TrimSpace(string(rest)))
-			postURL := "posts/" + strings.Replace(file.Name(), ".md", ".html", 1)
+			sources = append(sources, src)
+		}
+	}
+	return sources, nil
+}
 
-			post := Post{
-				Title:    title,
-				Date:     postDate,
-				Content:  htmlContent,
-				Filename: file.Name(),
-				URL:      postURL,
-			}
+func (s *Site) loadPosts() error {
+	sources, err := s.postSources()
+	if err != nil {
+		return err
+	}
 
-			s.Posts = append(s.Posts, post)
+	// Read and render posts in parallel, keeping them in directory order
+	posts := make([]*Post, len(sources))
+	err = parallel(len(sources), func(i int) error {
+		var err error
+		posts[i], err = s.loadPost(sources[i])
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		if post != nil {
+			s.Posts = append(s.Posts, *post)
 		}
 	}
 
 	// Sort posts by date (newest first)
-	sort.Slice(s.Posts, func(i, j int) bool {
+	sort.SliceStable(s.Posts, func(i, j int) bool {
 		return s.Posts[i].Date.After(s.Posts[j].Date)
 	})
 
+	if err := s.assignPermalinks(); err != nil {
+		return err
+	}
+
+	// Cover images may be relative to the post's bundle
+	for i := range s.Posts {
+		s.resolveImage(&s.Posts[i])
+	}
 	return nil
 }
 
-func (s *Site) loadPages() error {
-	pagesDir := "pages"
-	if _, err := os.Stat(pagesDir); os.IsNotExist(err) {
-		return nil // No pages directory
+// parsePostDate parses a date of a post, adding the separate time field
+// that new posts are created with when it is set. Dates without a time zone
+// are read in loc, the site's timezone, so the date a post shows and the
+// time it is published at agree.
+func parsePostDate(date, clock string, loc *time.Location) (time.Time, error) {
+	if clock != "" {
+		if parsed, err := dateparse.ParseIn(date+" "+clock, loc); err == nil {
+			return parsed, nil
+		}
 	}
+	return dateparse.ParseIn(date, loc)
+}
 
-	files, err := ioutil.ReadDir(pagesDir)
+// loadPost reads and renders a single post. It returns nil for files that
+// cannot be read and for drafts when they are not part of the build. Invalid
+// frontmatter is only an error in strict mode, which reports every problem
+// in the file at once.
+func (s *Site) loadPost(src postSource) (*Post, error) {
+	content, err := ioutil.ReadFile(src.path)
 	if err != nil {
-		return err
+		return nil, nil
 	}
 
-	for _, file := range files {
-		// Handle Markdown pages
-		if strings.HasSuffix(file.Name(), ".md") {
-			content, err := ioutil.ReadFile(filepath.Join(pagesDir, file.Name()))
-			if err != nil {
+	// Parse YAML, TOML or JSON frontmatter and content. Unknown keys leave
+	// the rest of the frontmatter usable, so checking carries on.
+	var matter PostMatter
+	var problems []error
+	rest, err := s.parseFrontmatter(src.path, content, &matter)
+	if err != nil {
+		if s.Options.Strict {
+			if rest == nil {
+				return nil, err
+			}
+			problems = append(problems, err)
+		} else {
+			// If frontmatter parsing fails, use defaults and clean filename
+			matter = PostMatter{Title: strings.TrimSpace(strings.ReplaceAll(src.name, "_", " "))}
+			rest = content
+		}
+	}
+
+	// Skip drafts unless they were requested
+	isDraft := matter.Draft || src.isDraftsDir
+	if isDraft && !s.Options.Drafts {
+		return nil, errors.Join(problems...)
+	}
+
+	// Use title from frontmatter or fallback to cleaned filename
+	title := matter.Title
+	if title == "" {
+		title = strings.TrimSpace(strings.ReplaceAll(src.name, "_", " "))
+	}
+
+	// Parse date with flexible parsing
+	var postDate time.Time
+	if matter.Date != "" {
+		if parsedDate, err := parsePostDate(string(matter.Date), matter.Time, s.loc); err == nil {
+			postDate = parsedDate
+		} else if s.Options.Strict {
+			problems = append(problems, strictError(src.path, content, "date", "cannot parse date %q", matter.Date))
+		} else {
+			// Fallback to file modification time
+			postDate = src.info.ModTime()
+		}
+	} else if s.Options.Strict {
+		problems = append(problems, &frontmatterError{File: src.path, Line: 1, Msg: "missing date"})
+	} else {
+		postDate = src.info.ModTime()
+	}
+
+	// An unparsable updated date is ignored rather than guessed
+	var updated time.Time
+	if matter.Updated != "" {
+		updated, err = parsePostDate(string(matter.Updated), "", s.loc)
+		if err != nil && s.Options.Strict {
+			problems = append(problems, strictError(src.path, content, "updated", "cannot parse updated date %q", matter.Updated))
+		}
+	}
+
+	// Leave out expired posts and, unless requested, posts not live yet
+	sched, err := s.postSchedule(src, content, matter)
+	if err != nil {
+		problems = append(problems, err)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	if !s.published(sched) {
+		return nil, nil
+	}
+
+	author := matter.Author
+	if author == "" {
+		author = s.Config.Author.Name
+	}
+
+	// Use slug from frontmatter or fallback to the filename
+	slug := matter.Slug
+	if slug == "" {
+		slug = slugify(src.name)
+	}
+
+	post := &Post{
+		Title:    title,
+		Date:     postDate,
+		Filename: src.filename,
+		Slug:     slug,
+		Draft:    isDraft,
+		Bundle:   src.bundle,
+
+		Tags:       matter.Tags,
+		Categories: matter.Categories,
+		Aliases:    matter.Aliases,
+		Series:     strings.TrimSpace(matter.Series),
+		SeriesPart: matter.SeriesPart,
+
+		Description: matter.Description,
+		Author:      author,
+		Updated:     updated,
+		Lang:        matter.Lang,
+		Image:       matter.Image,
+		Params:      matter.Params,
+
+		sourceHash: hashBytes(content),
+		publishAt:  sched.PublishAt,
+	}
+
+	// Relative links to the files of a bundle point at its copied assets
+	var links *bundleLinks
+	if src.bundle != "" {
+		links = newBundleLinks(src.bundle, expandPermalink(s.Config.Permalink, post))
+		// Adding or removing a bundle file changes which links are rebased
+		post.sourceHash = hashStrings(post.sourceHash, links.key())
+	}
+
+	// Convert markdown to HTML using enhanced Goldmark
+	body := strings.TrimSpace(string(rest))
+	rendered := s.markdownToHTML(body, links)
+	post.Content = rendered.HTML
+
+	post.WordCount = len(strings.Fields(plainText(post.Content)))
+	post.ReadingTime = readingTime(post.WordCount)
+	post.Summary = s.postSummary(matter.Description, body, post.Content)
+
+	// The table of contents is a site wide default that posts can override
+	showTOC := s.Config.TOC
+	if matter.TOC != nil {
+		showTOC = *matter.TOC
+	}
+	if showTOC {
+		post.TOC = rendered.TOC
+	}
+
+	return post, nil
+}
+
+// loadPages loads the pages of the site, including those in section
+// directories, and orders them for the navigation menus
+func (s *Site) loadPages() error {
+	var errs []error
+	for _, pagesDir := range s.contentDirs(s.Config.PagesPath()) {
+		if _, err := os.Stat(pagesDir); os.IsNotExist(err) {
+			continue // No pages directory
+		}
+
+		files, err := ioutil.ReadDir(pagesDir)
+		if err != nil {
+			return err
+		}
+
+		isDraftsDir := filepath.Base(pagesDir) == draftsDirName
+		for _, file := range files {
+			if file.IsDir() {
+				// Subdirectories of pages/ are sections, drafts/ aside.
+				// Those of drafts/ hold the unpublished pages of a section,
+				// which are loaded with it, unless the section has no
+				// published pages left.
+				if file.Name() == draftsDirName {
+					continue
+				}
+				sectionsDir := pagesDir
+				if isDraftsDir {
+					sectionsDir = filepath.Dir(pagesDir)
+					if info, err := os.Stat(filepath.Join(sectionsDir, file.Name())); err == nil && info.IsDir() {
+						continue
+					}
+				}
+				section, err := s.loadSection(sectionsDir, file.Name())
+				if err != nil {
+					errs = append(errs, err)
+				} else if section != nil {
+					s.Sections = append(s.Sections, section)
+				}
 				continue
 			}
 
-			// Parse frontmatter and content using the frontmatter library
-			var matter PageMatter
-			rest, err := frontmatter.Parse(strings.NewReader(string(content)), &matter)
+			page, err := s.loadPage(pagesDir, "", file, isDraftsDir)
 			if err != nil {
+				errs = append(errs, err)
+			} else if page != nil {
+				s.Pages = append(s.Pages, *page)
+			}
+		}
+	}
+
+	sortPages(s.Pages)
+	s.buildMenus()
+	return errors.Join(errs...)
+}
+
+// loadPage reads a markdown or HTML page from dir, which is section's
+// directory for pages in a section. It returns nil for files that are not
+// pages and for drafts that were not requested.
+func (s *Site) loadPage(dir, section string, file os.FileInfo, isDraftsDir bool) (*Page, error) {
+	name := file.Name()
+	filename := name
+	urlPath := "pages/"
+	if section != "" {
+		filename = section + "/" + name
+		urlPath += section + "/"
+	}
+
+	// Handle Markdown pages
+	if strings.HasSuffix(name, ".md") {
+		pagePath := filepath.Join(dir, name)
+		content, err := ioutil.ReadFile(pagePath)
+		if err != nil {
+			return nil, nil
+		}
+
+		// Parse YAML, TOML or JSON frontmatter and content
+		var matter PageMatter
+		var problems []error
+		rest, err := s.parseFrontmatter(pagePath, content, &matter)
+		if err != nil {
+			if s.Options.Strict {
+				if rest == nil {
+					return nil, err
+				}
+				problems = append(problems, err)
+			} else {
 				// If frontmatter parsing fails, use defaults and clean filename
-				cleanTitle := strings.TrimSpace(strings.ReplaceAll(file.Name(), "_", " "))
-				matter.Title = strings.TrimSuffix(cleanTitle, ".md")
+				matter.Title = defaultTitle(name)
 				rest = content
 			}
+		}
 
-			// Use title from frontmatter or fallback to cleaned filename
-			title := matter.Title
-			if title == "" {
-				cleanTitle := strings.TrimSpace(strings.ReplaceAll(file.Name(), "_", " "))
-				title = strings.TrimSuffix(cleanTitle, ".md")
+		if matter.Date != "" && s.Options.Strict {
+			if _, err := parsePostDate(string(matter.Date), "", s.loc); err != nil {
+				problems = append(problems, strictError(pagePath, content, "date", "cannot parse date %q", matter.Date))
 			}
+		}
+		if len(problems) > 0 {
+			return nil, errors.Join(problems...)
+		}
 
-			// Convert markdown to HTML using enhanced Goldmark
-			htmlContent := s.markdownToHTML(strings.TrimSpace(string(rest)))
-			pageURL := "pages/" + strings.Replace(file.Name(), ".md", ".html", 1)
+		// Skip drafts unless they were requested
+		isDraft := matter.Draft || isDraftsDir
+		if isDraft && !s.Options.Drafts {
+			return nil, nil
+		}
 
-			page := Page{
-				Title:    title,
-				Content:  htmlContent,
-				Filename: file.Name(),
-				URL:      pageURL,
-			}
+		// Use title from frontmatter or fallback to cleaned filename
+		title := matter.Title
+		if title == "" {
+			title = defaultTitle(name)
+		}
 
-			s.Pages = append(s.Pages, page)
+		// Convert markdown to HTML using enhanced Goldmark
+		htmlContent := s.markdownToHTML(strings.TrimSpace(string(rest)), nil).HTML
+		pageURL := urlPath + strings.Replace(name, ".md", ".html", 1)
+
+		return &Page{
+			Title:    title,
+			Content:  htmlContent,
+			Filename: filename,
+			URL:      pageURL,
+			Draft:    isDraft,
+			LastMod:  file.ModTime(),
+			Weight:   matter.Weight,
+			Menu:     matter.Menu,
+			Section:  section,
+
+			path:       pagePath,
+			sourceHash: hashBytes(content),
+		}, nil
+	}
 
-		} else if strings.HasSuffix(file.Name(), ".html") {
-			// Handle existing HTML pages (for backward compatibility)
-			content, err := ioutil.ReadFile(filepath.Join(pagesDir, file.Name()))
-			if err != nil {
-				continue
-			}
+	if strings.HasSuffix(name, ".html") {
+		// Handle existing HTML pages (for backward compatibility)
+		content, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, nil
+		}
 
-			title := strings.TrimSpace(strings.Replace(file.Name(), ".html", "", 1))
-			pageURL := "pages/" + file.Name()
+		title := strings.TrimSpace(strings.Replace(name, ".html", "", 1))
 
-			page := Page{
-				Title:    title,
-				Content:  string(content),
-				Filename: file.Name(),
-				URL:      pageURL,
-			}
+		return &Page{
+			Title:    title,
+			Content:  string(content),
+			Filename: filename,
+			URL:      urlPath + name,
+			Draft:    isDraftsDir,
+			LastMod:  file.ModTime(),
+			Section:  section,
 
-			s.Pages = append(s.Pages, page)
-		}
+			path:       filepath.Join(dir, name),
+			sourceHash: hashBytes(content),
+		}, nil
 	}
 
-	return nil
+	return nil, nil
 }
 
 func (s *Site) generateCSS() error {
@@ -401,7 +860,8 @@ p:last-child {
 	text-decoration: none;
 }
 
-.site-nav a:hover {
+.site-nav a:hover,
+.site-nav a[aria-current="page"] {
 	text-decoration: underline;
 }
 
@@ -437,10 +897,23 @@ p:last-child {
 	display: inline;
 }
 
+.post-summary {
+	color: var(--color-txt-light);
+	font-size: 0.875em;
+	margin: var(--space-XS) 0 0;
+}
+
 .post-content {
 	margin-top: var(--space-L);
 }
 
+.post-cover {
+	display: block;
+	max-width: 100%;
+	height: auto;
+	margin-top: var(--space-M);
+}
+
 .post-date {
 	color: var(--color-txt-light);
 	font-size: 0.875em;
@@ -478,281 +951,188 @@ main {
 .site-footer a:hover {
 	text-decoration: underline;
 }
-`
 
-	return ioutil.WriteFile(filepath.Join("public", "style.css"), []byte(cssContent), 0644)
-}
-
-func (s *Site) generateIndex() error {
-	indexTemplate := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{.Config.Title}}</title>
-    <meta name="description" content="{{.Config.Description}}">
-
-    <!-- Open Graph / Facebook -->
-    <meta property="og:type" content="website">
-    <meta property="og:url" content="{{.Config.BaseURL}}">
-    <meta property="og:title" content="{{.Config.Title}}">
-    <meta property="og:description" content="{{.Config.Description}}">
-    <meta property="og:site_name" content="{{.Config.Title}}">
-
-    <!-- X (Twitter) -->
-    <meta property="twitter:card" content="summary_large_image">
-    <meta property="twitter:url" content="{{.Config.BaseURL}}">
-    <meta property="twitter:title" content="{{.Config.Title}}">
-    <meta property="twitter:description" content="{{.Config.Description}}">
-
-    <!-- Additional SEO -->
-    <link rel="canonical" href="{{.Config.BaseURL}}">
-
-    <link rel="stylesheet" href="style.css">
-    <link rel="alternate" type="application/rss+xml" title="RSS Feed" href="feed.xml">
-</head>
-<body class="site-view">
-    <header class="site-header">
-        <div>
-            <h2><a href="/">{{.Config.Title}}</a></h2>
-            <nav class="site-nav">
-                {{range .Pages}}
-                <a href="{{.URL}}">{{.Title}}</a>
-                {{end}}
-            </nav>
-        </div>
-    </header>
-
-    <main class="site-main">
-        {{if .Config.Description}}
-        <div style="margin-bottom: 2rem;">
-            <p>{{.Config.Description}}</p>
-        </div>
-        {{end}}
-
-        <hr>
-
-        {{if .Posts}}
-        {{$currentYear := 0}}
-        {{range .Posts}}
-        {{$postYear := .Date.Year}}
-        {{if ne $postYear $currentYear}}
-        {{if ne $currentYear 0}}
-        </ul>
-        {{end}}
-        <h2>{{$postYear}}</h2>
-        <ul class="site-list-of-posts">
-        {{$currentYear = $postYear}}
-        {{end}}
-            <li>
-                <time>{{.Date.Format "2 Jan"}}</time>
-                <div class="post-link"><a href="{{.URL}}">{{.Title}}</a></div>
-            </li>
-        {{end}}
-        {{if .Posts}}
-        </ul>
-        {{end}}
-        {{end}}
-    </main>
-
-    <footer class="site-footer">
-        <p>
-            Made with <strong>BazelBlog</strong>
-            {{if .Config.Socials}}
-            | Connect with us:
-            {{range $platform, $url := .Config.Socials}}
-            <a href="{{$url}}">{{$platform}}</a>
-            {{end}}
-            {{end}}
-        </p>
-    </footer>
-</body>
-</html>`
-
-	tmpl, err := template.New("index").Funcs(sprig.FuncMap()).Parse(indexTemplate)
-	if err != nil {
-		return err
-	}
+.toc {
+	margin: var(--space-L) 0;
+	padding: var(--space-S) var(--space-L);
+	border-left: 3px solid var(--accent-color);
+}
 
-	file, err := os.Create(filepath.Join("public", "index.html"))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+.toc ul {
+	margin: 0;
+	padding-left: var(--space-L);
+}
 
-	return tmpl.Execute(file, s)
+.heading-anchor {
+	margin-left: 0.4em;
+	text-decoration: none;
+	opacity: 0;
 }
 
-func (s *Site) generatePosts() error {
-	postTemplate := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{.Title}} - {{.Config.Title}}</title>
-    <meta name="description" content="{{.Title}} - {{.Config.Description}}">
-
-    <!-- Open Graph / Facebook -->
-    <meta property="og:type" content="article">
-    <meta property="og:url" content="{{.Config.BaseURL}}/{{.URL}}">
-    <meta property="og:title" content="{{.Title}}">
-    <meta property="og:description" content="{{.Title}} - {{.Config.Description}}">
-    <meta property="og:site_name" content="{{.Config.Title}}">
-    <meta property="article:published_time" content="{{.Date.Format "2006-01-02T15:04:05Z07:00"}}">
-    <meta property="article:author" content="{{.Config.Title}}">
-
-    <!-- X (Twitter) -->
-    <meta property="twitter:card" content="summary_large_image">
-    <meta property="twitter:url" content="{{.Config.BaseURL}}/{{.URL}}">
-    <meta property="twitter:title" content="{{.Title}}">
-    <meta property="twitter:description" content="{{.Title}} - {{.Config.Description}}">
-
-    <!-- Additional SEO -->
-    <link rel="canonical" href="{{.Config.BaseURL}}/{{.URL}}">
-
-    <link rel="stylesheet" href="../style.css">
-</head>
-<body class="site-view">
-    <header class="site-header">
-        <div>
-            <h2><a href="../">{{.Config.Title}}</a></h2>
-            <nav class="site-nav">
-                <a href="../">Home</a>
-                {{range .Pages}}
-                <a href="../{{.URL}}">{{.Title}}</a>
-                {{end}}
-            </nav>
-        </div>
-    </header>
-
-    <main class="site-main">
-        <h1>{{.Title}}</h1>
-        <div class="post-date">{{.Date.Format "January 2, 2006"}}</div>
-        <div class="post-content">
-            {{.Content}}
-        </div>
-    </main>
-
-    <footer class="site-footer">
-        <p>
-            Made with <strong>BazelBlog</strong>
-            {{if .Config.Socials}}
-            | Connect with us:
-            {{range $platform, $url := .Config.Socials}}
-            <a href="{{$url}}">{{$platform}}</a>
-            {{end}}
-            {{end}}
-        </p>
-    </footer>
-</body>
-</html>`
-
-	tmpl, err := template.New("post").Parse(postTemplate)
-	if err != nil {
-		return err
-	}
+.heading-anchor::before {
+	content: "#";
+}
 
-	for _, post := range s.Posts {
-		file, err := os.Create(filepath.Join("public", post.URL))
-		if err != nil {
-			return err
-		}
+h1:hover .heading-anchor,
+h2:hover .heading-anchor,
+h3:hover .heading-anchor,
+h4:hover .heading-anchor,
+h5:hover .heading-anchor,
+h6:hover .heading-anchor,
+.heading-anchor:focus {
+	opacity: 1;
+}
 
-		data := struct {
-			Title   string
-			Date    time.Time
-			Content template.HTML
-			URL     string
-			Config  *config.Config
-			Pages   []Page
-		}{
-			Title:   post.Title,
-			Date:    post.Date,
-			Content: template.HTML(post.Content),
-			URL:     post.URL,
-			Config:  s.Config,
-			Pages:   s.Pages,
-		}
+.post-terms {
+	color: var(--color-txt-light);
+	font-size: 0.875em;
+	margin-top: var(--space-XL);
+}
 
-		err = tmpl.Execute(file, data)
-		file.Close()
-		if err != nil {
-			return err
-		}
-	}
+.post-terms a {
+	margin-right: var(--space-XS);
+	text-decoration: none;
+}
 
-	return nil
+.site-list-of-terms {
+	list-style: none;
+	padding: 0;
 }
 
-func (s *Site) generatePages() error {
-	pageTemplate := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{.Title}} - {{.Config.Title}}</title>
-    <meta name="description" content="{{.Title}} - {{.Config.Description}}">
-
-    <!-- Open Graph / Facebook -->
-    <meta property="og:type" content="website">
-    <meta property="og:url" content="{{.Config.BaseURL}}/{{.URL}}">
-    <meta property="og:title" content="{{.Title}}">
-    <meta property="og:description" content="{{.Title}} - {{.Config.Description}}">
-    <meta property="og:site_name" content="{{.Config.Title}}">
-
-    <!-- X (Twitter) -->
-    <meta property="twitter:card" content="summary_large_image">
-    <meta property="twitter:url" content="{{.Config.BaseURL}}/{{.URL}}">
-    <meta property="twitter:title" content="{{.Title}}">
-    <meta property="twitter:description" content="{{.Title}} - {{.Config.Description}}">
-
-    <!-- Additional SEO -->
-    <link rel="canonical" href="{{.Config.BaseURL}}/{{.URL}}">
-
-    <link rel="stylesheet" href="../style.css">
-</head>
-<body class="site-view">
-    <header class="site-header">
-        <div>
-            <h2><a href="../">{{.Config.Title}}</a></h2>
-            <nav class="site-nav">
-                <a href="../">Home</a>
-                {{range .Pages}}
-                <a href="../{{.URL}}">{{.Title}}</a>
-                {{end}}
-            </nav>
-        </div>
-    </header>
-
-    <main class="site-main">
-        {{.Content}}
-    </main>
-
-    <footer class="site-footer">
-        <p>
-            Made with <strong>BazelBlog</strong>
-            {{if .Config.Socials}}
-            | Connect with us:
-            {{range $platform, $url := .Config.Socials}}
-            <a href="{{$url}}">{{$platform}}</a>
-            {{end}}
-            {{end}}
-        </p>
-    </footer>
-</body>
-</html>`
-
-	tmpl, err := template.New("page").Parse(pageTemplate)
-	if err != nil {
-		return err
-	}
+.site-list-of-terms li:not(:first-of-type) {
+	margin-top: var(--space-XS);
+}
+
+.pagination {
+	display: flex;
+	justify-content: space-between;
+	margin-top: var(--space-XL);
+}
+
+.pagination a {
+	text-decoration: none;
+}
+
+.site-list-of-terms .count {
+	color: var(--color-txt-light);
+}
+
+.series {
+	margin: var(--space-L) 0;
+	padding: var(--space-S) var(--space-L);
+	border-left: 3px solid var(--accent-color);
+}
+
+.series-title {
+	margin: 0;
+	font-weight: bold;
+}
+
+.series ol {
+	margin: var(--space-XS) 0 0;
+}
+
+.series-nav {
+	display: flex;
+	justify-content: space-between;
+	gap: var(--space-M);
+	margin-top: var(--space-XL);
+}
 
-	for _, page := range s.Pages {
-		// Create output file
-		file, err := os.Create(filepath.Join("public", page.URL))
+.series-nav a {
+	text-decoration: none;
+}
+
+.series-nav .next {
+	margin-left: auto;
+	text-align: right;
+}
+
+.embed-video {
+	position: relative;
+	aspect-ratio: 16 / 9;
+	margin: var(--space-L) 0;
+}
+
+.embed-video iframe {
+	position: absolute;
+	width: 100%;
+	height: 100%;
+	border: 0;
+}
+
+figure {
+	margin: var(--space-L) 0;
+}
+
+figure img {
+	display: block;
+	max-width: 100%;
+	height: auto;
+}
+
+figcaption {
+	color: var(--color-txt-light);
+	font-size: 0.875em;
+	margin-top: var(--space-XS);
+}
+
+.callout {
+	margin: var(--space-L) 0;
+	padding: var(--space-S) var(--space-L);
+	border-left: 3px solid var(--accent-color);
+	border-radius: var(--border-radius);
+	background: rgba(var(--text-color), 0.05);
+}
+
+.callout-title {
+	font-weight: bold;
+}
+
+.callout-warning {
+	border-left-color: #e0a800;
+}
+
+.callout > :first-child {
+	margin-top: 0;
+}
+
+.callout > :last-child {
+	margin-bottom: 0;
+}
+`
+
+	return s.writeOutput("style.css", []byte(cssContent+s.highlightCSS()))
+}
+
+func (s *Site) generatePosts() error {
+	return parallel(len(s.Posts), func(i int) error {
+		post := &s.Posts[i]
+		description := post.Summary
+		if description == "" {
+			description = post.Title + " - " + s.Config.Description
+		}
+
+		err := s.renderLayout("post", outputFile(post.URL), LayoutData{
+			Title:       post.Title,
+			Description: description,
+			Date:        post.Date,
+			Content:     template.HTML(post.Content),
+			TOC:         template.HTML(post.TOC),
+			URL:         post.URL,
+			Permalink:   post.Permalink,
+			Post:        post,
+		})
 		if err != nil {
-			return err
+			return fmt.Errorf("%s: %w", post.Filename, err)
 		}
+		return nil
+	})
+}
+
+func (s *Site) generatePages() error {
+	return parallel(len(s.Pages), func(i int) error {
+		page := &s.Pages[i]
 
 		// For Markdown pages, Content is already processed HTML
 		// For HTML pages, we need to extract body content
@@ -762,13 +1142,7 @@ func (s *Site) generatePages() error {
 			pageContent = template.HTML(page.Content)
 		} else {
 			// HTML page - extract body content
-			src := filepath.Join("pages", page.Filename)
-			content, err := ioutil.ReadFile(src)
-			if err != nil {
-				return err
-			}
-
-			contentStr := string(content)
+			contentStr := page.Content
 			bodyStart := strings.Index(contentStr, "<body>")
 			bodyEnd := strings.Index(contentStr, "</body>")
 
@@ -779,100 +1153,100 @@ func (s *Site) generatePages() error {
 			}
 		}
 
-		data := struct {
-			Title   string
-			Content template.HTML
-			URL     string
-			Config  *config.Config
-			Pages   []Page
-		}{
-			Title:   page.Title,
-			Content: pageContent,
-			URL:     page.URL,
-			Config:  s.Config,
-			Pages:   s.Pages,
-		}
-
-		err = tmpl.Execute(file, data)
-		file.Close()
+		err := s.renderLayout("page", page.URL, LayoutData{
+			Title:       page.Title,
+			Description: page.Title + " - " + s.Config.Description,
+			Content:     pageContent,
+			URL:         page.URL,
+			Permalink:   s.absURL(page.URL),
+			Page:        page,
+		})
 		if err != nil {
-			return err
+			return fmt.Errorf("%s: %w", page.Filename, err)
 		}
-	}
+		return nil
+	})
+}
 
-	return nil
+// renderedMarkdown is the result of converting a markdown document
+type renderedMarkdown struct {
+	HTML string `json:"html"`
+	TOC  string `json:"toc,omitempty"` // Nested list of the document's headings
 }
 
-func (s *Site) generateRSS() error {
-	rssTemplate := `<?xml version="1.0" encoding="UTF-8" ?>
-<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
-	<channel>
-		<title>{{.Config.Title}}</title>
-		<description>{{.Config.Description}}</description>
-		<link>{{.Config.BaseURL}}</link>
-		<atom:link href="{{.Config.BaseURL}}/feed.xml" rel="self" type="application/rss+xml" />
-		<language>en-us</language>
-		<lastBuildDate>{{.BuildDate}}</lastBuildDate>
-		<generator>Bazel Static Site Generator</generator>
-		{{range .Posts}}
-		<item>
-			<title>{{.Title}}</title>
-			<description><![CDATA[{{.Content}}]]></description>
-			<link>{{$.Config.BaseURL}}/{{.URL}}</link>
-			<guid>{{$.Config.BaseURL}}/{{.URL}}</guid>
-			<pubDate>{{.Date.Format "Mon, 02 Jan 2006 15:04:05 -0700"}}</pubDate>
-		</item>
-		{{end}}
-	</channel>
-</rss>`
-
-	tmpl, err := template.New("rss").Parse(rssTemplate)
-	if err != nil {
-		return err
+// markdownToHTML converts markdown to HTML using enhanced Goldmark with
+// extensions. Relative links to the files of a bundle are rebased when links
+// is set.
+func (s *Site) markdownToHTML(markdown string, links *bundleLinks) renderedMarkdown {
+	// Reuse the HTML of unchanged sources from the previous build
+	key := hashStrings(s.markdownKey, links.key(), markdown)
+	if rendered, ok := s.cache.renderedMarkdown(key); ok {
+		return rendered
 	}
 
-	file, err := os.Create(filepath.Join("public", "feed.xml"))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+	ctx := parser.NewContext()
+	ctx.Set(bundleLinksKey, links)
 
-	data := struct {
-		*Site
-		BuildDate string
-	}{
-		Site:      s,
-		BuildDate: time.Now().Format("Mon, 02 Jan 2006 15:04:05 -0700"),
+	var buf bytes.Buffer
+	if err := s.markdown.Convert([]byte(markdown), &buf, parser.WithContext(ctx)); err != nil {
+		// Fallback to plain text if conversion fails
+		return renderedMarkdown{HTML: markdown}
 	}
 
-	return tmpl.Execute(file, data)
+	headings, _ := ctx.Get(tocKey).([]tocHeading)
+	rendered := renderedMarkdown{HTML: buf.String(), TOC: tocHTML(headings)}
+	s.cache.storeMarkdown(key, rendered)
+	return rendered
 }
 
-// markdownToHTML converts markdown to HTML using enhanced Goldmark with extensions
-func (s *Site) markdownToHTML(markdown string) string {
-	// Configure Goldmark with extensions
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,           // GitHub Flavored Markdown
-			extension.Table,         // Tables
-			extension.Strikethrough, // Strikethrough
-			extension.Linkify,       // Auto-linkify URLs
-			extension.TaskList,      // Task lists
-		),
+// newMarkdown configures the Goldmark engine shared by every conversion in
+// a build. Per document state travels in the parser context, so the engine
+// is safe to use from several goroutines.
+func newMarkdown(cfg *config.Config, shortcodes *shortcodeSet) goldmark.Markdown {
+	extensions := []goldmark.Extender{
+		extension.GFM,                  // GitHub Flavored Markdown
+		extension.Table,                // Tables
+		extension.Strikethrough,        // Strikethrough
+		extension.Linkify,              // Auto-linkify URLs
+		extension.TaskList,             // Task lists
+		highlightExtension(cfg),        // Syntax highlighting
+		shortcodeExtension{shortcodes}, // {{< name args >}} embeds
+	}
+
+	// Optional extensions from the [markdown] section of bazel.toml
+	opts := cfg.Markdown
+	if opts.Footnotes {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if opts.DefinitionLists {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+	if opts.Typographer {
+		extensions = append(extensions, extension.Typographer)
+	}
+	if opts.Math {
+		extensions = append(extensions, mathExtension{})
+	}
+
+	rendererOptions := []renderer.Option{
+		goldmarkhtml.WithXHTML(), // XHTML output
+	}
+	if opts.HardWrapsEnabled() {
+		rendererOptions = append(rendererOptions, goldmarkhtml.WithHardWraps())
+	}
+	if opts.UnsafeEnabled() {
+		rendererOptions = append(rendererOptions, goldmarkhtml.WithUnsafe())
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(extensions...),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(), // Auto-generate heading IDs
+			parser.WithASTTransformers(
+				util.Prioritized(bundleLinkTransformer{}, 100),
+				util.Prioritized(headingTransformer{}, 200),
+			),
 		),
-		goldmark.WithRendererOptions(
-			goldmarkhtml.WithHardWraps(), // Hard line breaks
-			goldmarkhtml.WithXHTML(),     // XHTML output
-			goldmarkhtml.WithUnsafe(),    // Allow raw HTML
-		),
+		goldmark.WithRendererOptions(rendererOptions...),
 	)
-
-	var buf bytes.Buffer
-	if err := md.Convert([]byte(markdown), &buf); err != nil {
-		// Fallback to plain text if conversion fails
-		return markdown
-	}
-	return buf.String()
 }
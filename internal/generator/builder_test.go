@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/bazel_blog/internal/config"
+)
+
+func TestParsePostDate(t *testing.T) {
+	tests := []struct {
+		date, clock string
+		want        time.Time
+	}{
+		{"2024-01-02", "", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2024-01-02", "15:04", time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"2024-01-02 08:30", "", time.Date(2024, 1, 2, 8, 30, 0, 0, time.UTC)},
+		{"2024-01-02", "not a time", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parsePostDate(tt.date, tt.clock, time.UTC)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parsePostDate(%q, %q) = %v, %v, want %v", tt.date, tt.clock, got, err, tt.want)
+		}
+	}
+	if _, err := parsePostDate("someday", "", time.UTC); err == nil {
+		t.Error("parsePostDate accepted an invalid date")
+	}
+}
+
+func TestResolveImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		post   Post
+		path   string
+		imgURL string
+	}{
+		{"none", Post{URL: "posts/a.html"}, "", ""},
+		{"external", Post{Image: "https://cdn.example.org/a.png"}, "", "https://cdn.example.org/a.png"},
+		{"root relative", Post{Image: "/img/a.png", URL: "posts/a.html"}, "img/a.png", "https://example.com/img/a.png"},
+		{"site relative", Post{Image: "./img/a.png", URL: "posts/a.html"}, "img/a.png", "https://example.com/img/a.png"},
+		{"bundle", Post{Image: "cover.jpg", URL: "posts/trip.html", Bundle: "posts/trip"}, "posts/trip/cover.jpg", "https://example.com/posts/trip/cover.jpg"},
+	}
+
+	s := &Site{Config: &config.Config{BaseURL: "https://example.com/"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := tt.post
+			s.resolveImage(&post)
+			if post.ImagePath != tt.path || post.ImageURL != tt.imgURL {
+				t.Errorf("image = %q, %q, want %q, %q", post.ImagePath, post.ImageURL, tt.path, tt.imgURL)
+			}
+		})
+	}
+}
+
+func TestPostFrontmatterFields(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml": "title = \"Fields\"\nbase_url = \"https://example.com\"\n[author]\nname = \"Site Author\"\n",
+		"posts/full.md": markdownFile(`title: Full
+date: 2024-01-02
+time: "09:30"
+updated: 2024-03-04
+author: Guest Writer
+image: /img/cover.png
+lang: de
+params:
+  mood: happy`, "Hallo"),
+		"posts/plain.md": markdownFile("title: Plain\ndate: 2024-01-01", "Hello"),
+		"themes/default/layouts/partials/footer.html": `<footer>{{with .Post}}{{.Params.mood}}{{end}}</footer>`,
+	})
+	buildTestSite(t)
+
+	assertContains(t, "posts/full.html",
+		`<html lang="de">`,
+		`<meta property="article:published_time" content="2024-01-02T09:30:00Z">`,
+		`<meta property="article:modified_time" content="2024-03-04T00:00:00Z">`,
+		`<meta property="article:author" content="Guest Writer">`,
+		`<meta property="og:image" content="https://example.com/img/cover.png">`,
+		`<meta property="twitter:card" content="summary_large_image">`,
+		"· Guest Writer · Updated March 4, 2024 ·",
+		`<img class="post-cover" src="../img/cover.png" alt="">`,
+		"<footer>happy</footer>")
+	assertContains(t, "posts/plain.html",
+		`<html lang="en">`,
+		`<meta property="article:author" content="Site Author">`,
+		`<meta property="twitter:card" content="summary">`)
+	assertNotContains(t, "posts/plain.html", "article:modified_time", "post-cover", "Updated")
+
+	assertContains(t, "feed.xml",
+		"<dc:creator>Guest Writer</dc:creator>",
+		`<enclosure url="https://example.com/img/cover.png" length="0" type="image/png" />`)
+	assertContains(t, "atom.xml", "<updated>2024-03-04T00:00:00Z</updated>", "<name>Guest Writer</name>")
+	assertContains(t, "feed.json", `"date_modified": "2024-03-04T00:00:00Z"`, `"language": "de"`, `"image": "https://example.com/img/cover.png"`)
+	assertContains(t, "sitemap.xml", "<lastmod>2024-03-04T00:00:00Z</lastmod>")
+}
+
+func TestDirectorySettings(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":             "title = \"Dirs\"\noutput_dir = \"dist\"\ncontent_dir = \"content\"\nstatic_dir = \"assets\"\n",
+		"content/posts/hello.md": markdownFile("title: Hello\ndate: 2024-01-02", "Hello"),
+		"content/pages/about.md": markdownFile("title: About", "About"),
+		"assets/favicon.ico":     "icon",
+		"posts/ignored.md":       markdownFile("title: Ignored\ndate: 2024-01-03", "Not content"),
+	})
+	buildTestSite(t)
+
+	for _, name := range []string{"dist/posts/hello.html", "dist/pages/about.html", "dist/favicon.ico"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("%s was not built: %v", name, err)
+		}
+	}
+	for _, name := range []string{"public", "dist/posts/ignored.html"} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%s exists", name)
+		}
+	}
+
+	filename, err := CreatePost("New One")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filename != filepath.Join("content", "posts", "New_One.md") {
+		t.Errorf("CreatePost wrote %s, want it in content/posts", filename)
+	}
+	posts, err := ListPosts()
+	if err != nil || len(posts) != 2 {
+		t.Errorf("ListPosts() = %q, %v, want the posts in content/posts", posts, err)
+	}
+}
@@ -0,0 +1,252 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// cacheDir holds the build manifest between builds
+const cacheDir = ".bazel-cache"
+
+// cacheVersion invalidates old manifests when the cache format or the
+// rendering changes
+const cacheVersion = 4
+
+// buildCache remembers what the previous build wrote so unchanged content is
+// neither re-rendered nor rewritten, and outputs that are no longer
+// generated can be removed.
+type buildCache struct {
+	Version   int                         `json:"version"`
+	OutputDir string                      `json:"output_dir"` // Where the outputs were written
+	Outputs   map[string]cachedOutput     `json:"outputs"`    // Keyed by path relative to public/
+	Markdown  map[string]renderedMarkdown `json:"markdown"`   // Keyed by source hash
+
+	// Entries used by the current build, saved as the next manifest
+	mu       sync.Mutex
+	outputs  map[string]cachedOutput
+	markdown map[string]renderedMarkdown
+}
+
+// cachedOutput describes a file written to public/
+type cachedOutput struct {
+	Hash string `json:"hash"`          // Hash of the file content
+	Key  string `json:"key,omitempty"` // Hash of everything a rendered page depends on
+}
+
+func manifestPath() string {
+	return filepath.Join(cacheDir, "manifest.json")
+}
+
+// loadCache reads the manifest of the previous build. A missing or outdated
+// manifest, or one for another output directory, results in an empty cache
+// and a full build.
+func loadCache(outputDir string) *buildCache {
+	cache := &buildCache{
+		outputs:  make(map[string]cachedOutput),
+		markdown: make(map[string]renderedMarkdown),
+	}
+
+	content, err := os.ReadFile(manifestPath())
+	if err == nil && json.Unmarshal(content, cache) == nil && cache.Version == cacheVersion && cache.OutputDir == outputDir {
+		return cache
+	}
+
+	cache.OutputDir = outputDir
+	cache.Outputs = make(map[string]cachedOutput)
+	cache.Markdown = make(map[string]renderedMarkdown)
+	return cache
+}
+
+// save writes the entries used by the current build as the new manifest
+func (c *buildCache) save() error {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(buildCache{
+		Version:   cacheVersion,
+		OutputDir: c.OutputDir,
+		Outputs:   c.outputs,
+		Markdown:  c.markdown,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath(), content, 0644)
+}
+
+// renderedMarkdown returns the cached rendering of a markdown source
+func (c *buildCache) renderedMarkdown(key string) (renderedMarkdown, bool) {
+	rendered, ok := c.Markdown[key]
+	if ok {
+		c.storeMarkdown(key, rendered)
+	}
+	return rendered, ok
+}
+
+func (c *buildCache) storeMarkdown(key string, rendered renderedMarkdown) {
+	c.mu.Lock()
+	c.markdown[key] = rendered
+	c.mu.Unlock()
+}
+
+// storeOutput records a file written, or kept, by the current build
+func (c *buildCache) storeOutput(outPath string, output cachedOutput) {
+	c.mu.Lock()
+	c.outputs[outPath] = output
+	c.mu.Unlock()
+}
+
+// hasOutput reports whether the current build produced outPath
+func (c *buildCache) hasOutput(outPath string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.outputs[outPath]
+	return ok
+}
+
+// hashStrings returns a hex encoded hash of the given values
+func hashStrings(values ...string) string {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashBytes(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// computeSiteKey hashes what every rendered page may depend on: the config,
+// the layouts and shortcodes, the build options, the menus and everything
+// layouts can read from the lists of posts and pages, except their rendered
+// content. Editing the body of one post leaves it unchanged, so only that
+// post is rendered.
+func (s *Site) computeSiteKey() {
+	// The rendered Content and TOC are left out, and SeriesNav follows from
+	// the series fields. Params are printed since YAML maps are not JSON.
+	type postMeta struct {
+		Post
+		Params string
+	}
+
+	meta := struct {
+		Config  interface{}
+		Options BuildOptions
+		Posts   []postMeta
+		Pages   []Page
+		Menus   map[string][]MenuItem
+	}{Config: s.Config, Options: s.Options, Menus: s.Menus}
+
+	for _, post := range s.Posts {
+		post.Content, post.TOC, post.SeriesNav = "", "", nil
+		meta.Posts = append(meta.Posts, postMeta{Post: post, Params: fmt.Sprint(post.Params)})
+	}
+	for _, page := range s.Pages {
+		page.Content = ""
+		meta.Pages = append(meta.Pages, page)
+	}
+
+	content, _ := json.Marshal(meta)
+	s.siteKey = hashStrings(string(content), s.layoutHash, s.markdownKey)
+}
+
+// computeMarkdownKey hashes the config settings and shortcode templates that
+// change how markdown is rendered, so cached HTML is discarded when they change
+func (s *Site) computeMarkdownKey() {
+	content, _ := json.Marshal([]interface{}{s.Config.Highlight, s.Config.Markdown})
+	s.markdownKey = hashStrings(string(content), s.shortcodes.hash)
+}
+
+// renderKey identifies the inputs of a post or page. Other pages list many
+// posts and are always rendered, but only written when they change.
+func (s *Site) renderKey(kind string, data LayoutData) string {
+	switch {
+	case data.Post != nil:
+		return hashStrings(s.siteKey, kind, data.Post.sourceHash)
+	case data.Page != nil:
+		return hashStrings(s.siteKey, kind, data.Page.sourceHash)
+	case data.Section != nil:
+		return hashStrings(s.siteKey, kind, data.Section.sourceHash)
+	}
+	return ""
+}
+
+// upToDate reports whether outPath was rendered from the same inputs by the
+// previous build and still exists, keeping it in the manifest if so.
+func (s *Site) upToDate(outPath, key string) bool {
+	prev, ok := s.cache.Outputs[outPath]
+	if !ok || key == "" || prev.Key != key {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(s.Config.OutputPath(), outPath)); err != nil {
+		return false
+	}
+	s.cache.storeOutput(outPath, prev)
+	return true
+}
+
+// writeOutput writes content to outPath, relative to public/, unless the file
+// already holds exactly that content. Leaving unchanged files alone keeps
+// their modification times, which matters to deploy tools and live reload.
+func (s *Site) writeOutput(outPath string, content []byte) error {
+	return s.writeRendered(outPath, content, "")
+}
+
+// writeRendered is writeOutput for pages rendered from a known set of inputs
+func (s *Site) writeRendered(outPath string, content []byte, key string) error {
+	outPath = filepath.ToSlash(outPath)
+	hash := hashBytes(content)
+	s.cache.storeOutput(outPath, cachedOutput{Hash: hash, Key: key})
+
+	target := filepath.Join(s.Config.OutputPath(), outPath)
+	if prev, ok := s.cache.Outputs[outPath]; ok && prev.Hash == hash {
+		if existing, err := os.ReadFile(target); err == nil && bytes.Equal(existing, content) {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, content, 0644)
+}
+
+// removeStaleOutputs deletes files written by the previous build that the
+// current build no longer generates, along with directories left empty.
+func (s *Site) removeStaleOutputs() error {
+	var stale []string
+	for outPath := range s.cache.Outputs {
+		if !s.cache.hasOutput(outPath) {
+			stale = append(stale, outPath)
+		}
+	}
+	sort.Strings(stale)
+
+	outputDir := s.Config.OutputPath()
+	for _, outPath := range stale {
+		target := filepath.Join(outputDir, filepath.FromSlash(outPath))
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		// Remove parent directories that are now empty
+		for dir := filepath.Dir(target); dir != outputDir && strings.HasPrefix(dir, outputDir); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var cacheSite = map[string]string{
+	"bazel.toml":          "title = \"Cache Test\"\nbase_url = \"https://example.com\"\n",
+	"posts/hello.md":      markdownFile("title: Hello\ndate: 2024-01-02", "First version"),
+	"posts/old.md":        markdownFile("title: Old\ndate: 2023-05-06", "An old post"),
+	"posts/trip/index.md": markdownFile("title: Trip\ndate: 2024-02-03", "![Photo](photo.jpg)"),
+}
+
+func TestIncrementalBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  map[string]string
+		file    string // Output checked after the second build
+		want    string // Expected in file, or empty when file must be gone
+		touched string // Output that must not be rewritten
+	}{
+		{
+			name:    "source edit",
+			change:  map[string]string{"posts/hello.md": markdownFile("title: Hello\ndate: 2024-01-02", "Second version")},
+			file:    "posts/hello.html",
+			want:    "Second version",
+			touched: "posts/old.html",
+		},
+		{
+			name:   "title edit",
+			change: map[string]string{"posts/old.md": markdownFile("title: Retitled\ndate: 2023-05-06", "An old post")},
+			file:   "index.html",
+			want:   "Retitled",
+		},
+		{
+			name:   "config change",
+			change: map[string]string{"bazel.toml": "title = \"Renamed\"\nbase_url = \"https://example.com\"\n"},
+			file:   "posts/old.html",
+			want:   "Renamed",
+		},
+		{
+			name:   "theme layout change",
+			change: map[string]string{"themes/default/layouts/partials/footer.html": "<footer>Custom footer</footer>"},
+			file:   "posts/old.html",
+			want:   "Custom footer",
+		},
+		{
+			name:   "bundle file added",
+			change: map[string]string{"posts/trip/photo.jpg": "jpeg"},
+			file:   "posts/trip.html",
+			want:   `src="trip/photo.jpg"`,
+		},
+		{
+			name:   "deleted post",
+			change: map[string]string{"posts/old.md": ""},
+			file:   "posts/old.html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestSite(t, cacheSite)
+			buildTestSite(t)
+
+			// Backdate an output the change must leave alone
+			past := time.Now().Add(-time.Hour).Truncate(time.Second)
+			if tt.touched != "" {
+				if err := os.Chtimes(filepath.Join("public", tt.touched), past, past); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			writeSite(t, tt.change)
+			buildTestSite(t)
+
+			if tt.want == "" {
+				if hasOutput(tt.file) {
+					t.Errorf("%s was not removed", tt.file)
+				}
+				if !hasOutput("posts") {
+					t.Errorf("posts directory was removed with %s", tt.file)
+				}
+			} else if got := readOutput(t, tt.file); !strings.Contains(got, tt.want) {
+				t.Errorf("%s does not contain %q:\n%s", tt.file, tt.want, got)
+			}
+
+			if tt.touched != "" {
+				info, err := os.Stat(filepath.Join("public", tt.touched))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !info.ModTime().Equal(past) {
+					t.Errorf("%s was rewritten although its inputs did not change", tt.touched)
+				}
+			}
+		})
+	}
+}
+
+func TestCleanBuild(t *testing.T) {
+	newTestSite(t, cacheSite)
+	buildTestSite(t)
+	writeSite(t, map[string]string{"public/stray.html": "stray"})
+
+	if err := BuildSiteWithOptions(BuildOptions{Clean: true}); err != nil {
+		t.Fatal(err)
+	}
+	if hasOutput("stray.html") {
+		t.Error("--clean left a file that is not generated")
+	}
+	assertContains(t, "posts/hello.html", "First version")
+}
+
+func TestIncrementalBuildListedFields(t *testing.T) {
+	layout := `{{define "main"}}{{range .Posts}}<li>{{.Title}}|{{.Summary}}|{{.Author}}|{{.Image}}|{{.ReadingTime}}|{{.Params.mood}}</li>{{end}}{{end}}`
+	tests := []struct {
+		name  string
+		other string // New source of posts/other.md
+		want  string // Expected on the unchanged post's page
+	}{
+		{
+			name:  "summary",
+			other: markdownFile("title: Other\ndate: 2024-01-03\ndescription: Rewritten", "Other post"),
+			want:  "|Rewritten|",
+		},
+		{
+			name:  "author",
+			other: markdownFile("title: Other\ndate: 2024-01-03\nauthor: Someone Else", "Other post"),
+			want:  "|Someone Else|",
+		},
+		{
+			name:  "image",
+			other: markdownFile("title: Other\ndate: 2024-01-03\nimage: cover.jpg", "Other post"),
+			want:  "|cover.jpg|",
+		},
+		{
+			name:  "reading time",
+			other: markdownFile("title: Other\ndate: 2024-01-03", strings.Repeat("word ", 1000)),
+			want:  "|5|",
+		},
+		{
+			name:  "params",
+			other: markdownFile("title: Other\ndate: 2024-01-03\nparams:\n  mood: happy\n  nested: {a: 1}", "Other post"),
+			want:  "|happy</li>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestSite(t, map[string]string{
+				"themes/default/layouts/post.html": layout,
+				"posts/hello.md":                   markdownFile("title: Hello\ndate: 2024-01-02", "Hello"),
+				"posts/other.md":                   markdownFile("title: Other\ndate: 2024-01-03", "Other post"),
+			})
+			buildTestSite(t)
+
+			writeSite(t, map[string]string{"posts/other.md": tt.other})
+			buildTestSite(t)
+			assertContains(t, "posts/hello.html", tt.want)
+		})
+	}
+}
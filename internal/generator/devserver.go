@@ -11,16 +11,28 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/yourusername/bazel_blog/internal/config"
 )
 
 // StartDevServer starts a development server with live reload
 func StartDevServer() error {
+	return StartDevServerWithOptions(BuildOptions{})
+}
+
+// StartDevServerWithOptions starts a development server that builds the
+// site with the given options on every change
+func StartDevServerWithOptions(opts BuildOptions) error {
 	// First build the site
-	if err := BuildSite(); err != nil {
+	if err := BuildSiteWithOptions(opts); err != nil {
 		return fmt.Errorf("failed to build site: %w", err)
 	}
 	updateModTime()
 
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
 	// Start file watcher
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -28,22 +40,30 @@ func StartDevServer() error {
 	}
 	defer watcher.Close()
 
-	// Watch for changes in source directories
-	watchDirs := []string{"posts", "pages", "themes", "."}
-	for _, dir := range watchDirs {
-		if _, err := os.Stat(dir); err == nil {
-			if err := watcher.Add(dir); err != nil {
-				log.Printf("Warning: failed to watch directory %s: %v", dir, err)
-			}
-		}
+	// Watch for changes in the site directory
+	if err := watcher.Add("."); err != nil {
+		log.Printf("Warning: failed to watch directory .: %v", err)
 	}
 
-	// Explicitly watch the config file
-	if _, err := os.Stat("bazel.toml"); err == nil {
-		if err := watcher.Add("bazel.toml"); err != nil {
-			log.Printf("Warning: failed to watch bazel.toml: %v", err)
-		} else {
-			log.Println("📝 Watching bazel.toml for configuration changes")
+	// Page bundles, sections, static files and theme layouts live in
+	// nested directories, so watch the whole tree
+	watchTree(watcher, cfg.PostsPath())
+	watchTree(watcher, cfg.PagesPath())
+	watchTree(watcher, cfg.StaticPath())
+	watchTree(watcher, "themes")
+
+	// Explicitly watch the config file and the environment's overlay
+	configFiles := []string{config.Path}
+	if config.Env != "" {
+		configFiles = append(configFiles, config.EnvPath(config.Env))
+	}
+	for _, path := range configFiles {
+		if _, err := os.Stat(path); err == nil {
+			if err := watcher.Add(path); err != nil {
+				log.Printf("Warning: failed to watch %s: %v", path, err)
+			} else {
+				log.Printf("📝 Watching %s for configuration changes", path)
+			}
 		}
 	}
 
@@ -61,13 +81,7 @@ func StartDevServer() error {
 					return
 				}
 
-				// Only rebuild on write events and ignore temporary files
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					if filepath.Ext(event.Name) == ".tmp" ||
-						filepath.Base(event.Name)[0] == '.' {
-						continue
-					}
-
+				if needsRebuild(watcher, event) {
 					log.Printf("File changed: %s", event.Name)
 
 					// Debounce rebuilds (wait 500ms after last change)
@@ -75,7 +89,7 @@ func StartDevServer() error {
 					go func() {
 						<-debounceTimer.C
 						log.Println("Rebuilding site...")
-						if err := BuildSite(); err != nil {
+						if err := BuildSiteWithOptions(opts); err != nil {
 							log.Printf("Build error: %v", err)
 						} else {
 							log.Println("Site rebuilt successfully")
@@ -93,9 +107,9 @@ func StartDevServer() error {
 	}()
 
 	// Set up HTTP server
-	publicDir := "public"
+	publicDir := cfg.OutputPath()
 	if _, err := os.Stat(publicDir); os.IsNotExist(err) {
-		return fmt.Errorf("public directory not found - please build the site first")
+		return fmt.Errorf("%s directory not found - please build the site first", publicDir)
 	}
 
 	// Create file server with live reload injection
@@ -112,14 +126,49 @@ func StartDevServer() error {
 
 	port := "3000"
 	log.Printf("🚀 Development server starting on http://localhost:%s", port)
-	log.Println("📁 Serving files from public/")
+	log.Printf("📁 Serving files from %s/", publicDir)
 	log.Println("👀 Watching for file changes...")
 	log.Println("🔄 Live reload enabled")
+	if opts.Drafts {
+		log.Println("📝 Including draft posts and pages")
+	}
 	log.Println("Press Ctrl+C to stop")
 
 	return http.ListenAndServe(":"+port, nil)
 }
 
+// needsRebuild reports whether a watcher event changes the site. Directories
+// created after the server started, such as a new page bundle or section, are
+// watched from then on. Deleted and renamed files count as changes so their
+// stale output is removed. Temporary and hidden files are ignored.
+func needsRebuild(watcher *fsnotify.Watcher, event fsnotify.Event) bool {
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+		return false
+	}
+	if filepath.Ext(event.Name) == ".tmp" || strings.HasPrefix(filepath.Base(event.Name), ".") {
+		return false
+	}
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			watchTree(watcher, event.Name)
+		}
+	}
+	return true
+}
+
+// watchTree adds dir and all of its subdirectories to the watcher
+func watchTree(watcher *fsnotify.Watcher, dir string) {
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			log.Printf("Warning: failed to watch directory %s: %v", path, err)
+		}
+		return nil
+	})
+}
+
 // liveReloadFS wraps http.FileSystem to inject live reload script
 type liveReloadFS struct {
 	fs http.FileSystem
@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestNeedsRebuild(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/hello.md":      markdownFile("title: Hello\ndate: 2024-01-02", "Hello"),
+		"posts/trip/index.md": markdownFile("title: Trip\ndate: 2024-01-03", "Trip"),
+	})
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	watchTree(watcher, "posts")
+
+	tests := []struct {
+		name string
+		op   fsnotify.Op
+		want bool
+	}{
+		{"posts/hello.md", fsnotify.Write, true},
+		{"posts/new.md", fsnotify.Create, true},
+		{"posts/hello.md", fsnotify.Remove, true},
+		{"posts/trip/photo.jpg", fsnotify.Rename, true},
+		{"posts/hello.md", fsnotify.Chmod, false},
+		{"posts/hello.md.tmp", fsnotify.Write, false},
+		{"posts/.hello.md.swp", fsnotify.Remove, false},
+	}
+	for _, tt := range tests {
+		event := fsnotify.Event{Name: filepath.FromSlash(tt.name), Op: tt.op}
+		if got := needsRebuild(watcher, event); got != tt.want {
+			t.Errorf("needsRebuild(%s %s) = %v, want %v", tt.op, tt.name, got, tt.want)
+		}
+	}
+
+	// A bundle created while serving is watched along with its subdirectories
+	bundle := filepath.Join("posts", "hike")
+	if err := os.MkdirAll(filepath.Join(bundle, "maps"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !needsRebuild(watcher, fsnotify.Event{Name: bundle, Op: fsnotify.Create}) {
+		t.Error("a new bundle directory does not trigger a rebuild")
+	}
+	for _, dir := range []string{bundle, filepath.Join(bundle, "maps")} {
+		if !slices.Contains(watcher.WatchList(), dir) {
+			t.Errorf("%s is not watched, watching %q", dir, watcher.WatchList())
+		}
+	}
+}
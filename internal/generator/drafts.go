@@ -0,0 +1,305 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/adrg/frontmatter"
+)
+
+// draftsDirName is the subdirectory of posts/ and pages/ holding drafts
+const draftsDirName = "drafts"
+
+// draftMatter is the subset of frontmatter needed to detect drafts
+type draftMatter struct {
+	Draft bool `yaml:"draft" toml:"draft" json:"draft"`
+}
+
+// draftLine matches a draft flag inside YAML or TOML frontmatter
+var draftLine = regexp.MustCompile(`^draft\s*[:=]`)
+
+// isDraftFile reports whether a content file is marked `draft: true`
+func isDraftFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	var matter draftMatter
+	if _, err := frontmatter.Parse(file, &matter); err != nil {
+		return false
+	}
+	return matter.Draft
+}
+
+// listDrafts returns the names of drafts found in contentDir, either in its
+// drafts/ subdirectory or marked with `draft: true` in the frontmatter.
+func listDrafts(contentDir string, exts ...string) ([]string, error) {
+	var drafts []string
+
+	for _, dir := range []string{filepath.Join(contentDir, draftsDirName), contentDir} {
+		files, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		inDraftsDir := filepath.Base(dir) == draftsDirName
+		for _, file := range files {
+			if file.IsDir() {
+				index, ok := bundleIndexFile(filepath.Join(dir, file.Name()))
+				if ok && file.Name() != draftsDirName && hasExt(".md", exts) &&
+					(inDraftsDir || isDraftFile(index)) {
+					drafts = append(drafts, file.Name())
+				}
+				continue
+			}
+
+			ext := filepath.Ext(file.Name())
+			if !hasExt(ext, exts) {
+				continue
+			}
+			if inDraftsDir || isDraftFile(filepath.Join(dir, file.Name())) {
+				drafts = append(drafts, strings.TrimSuffix(file.Name(), ext))
+			}
+		}
+	}
+
+	return drafts, nil
+}
+
+func hasExt(ext string, exts []string) bool {
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// findContentFile locates name in contentDir or its drafts/ subdirectory,
+// either as a file or as the index.md of a page bundle
+func findContentFile(contentDir, name string, exts ...string) (string, error) {
+	for _, dir := range []string{contentDir, filepath.Join(contentDir, draftsDirName)} {
+		for _, ext := range exts {
+			path := filepath.Join(dir, name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+		if index, ok := bundleIndexFile(filepath.Join(dir, name)); ok && hasExt(".md", exts) {
+			return index, nil
+		}
+	}
+	return "", fmt.Errorf("not found: %s", name)
+}
+
+// publish moves a draft out of the drafts/ directory and clears its draft flag
+func publish(contentDir, name string, exts ...string) error {
+	path, err := findContentFile(contentDir, name, exts...)
+	if err != nil {
+		return err
+	}
+
+	// Page bundles move as a whole directory
+	src := path
+	if filepath.Base(path) == bundleIndex {
+		src = filepath.Dir(path)
+	}
+
+	// Drafts of a section move back into it
+	draftsDir := filepath.Join(contentDir, draftsDirName)
+	if rel, err := filepath.Rel(draftsDir, src); err == nil && !strings.HasPrefix(rel, "..") {
+		target := filepath.Join(contentDir, rel)
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("cannot publish %s: %s already exists", name, target)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.Rename(src, target); err != nil {
+			return fmt.Errorf("failed to move draft: %w", err)
+		}
+		path = filepath.Join(target, strings.TrimPrefix(path, src))
+	}
+
+	return removeDraftFlag(path)
+}
+
+// unpublish moves published content into the drafts/ directory. Pages of a
+// section keep their section directory, as in drafts/docs/install.md.
+func unpublish(contentDir, name string, exts ...string) error {
+	paths := make([]string, 0, len(exts)+1)
+	for _, ext := range exts {
+		paths = append(paths, filepath.Join(contentDir, name+ext))
+	}
+	if _, ok := bundleIndexFile(filepath.Join(contentDir, name)); ok && hasExt(".md", exts) {
+		paths = append(paths, filepath.Join(contentDir, name))
+	}
+
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(contentDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(contentDir, draftsDirName, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create drafts directory: %w", err)
+		}
+
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("a draft named %s already exists", name)
+		}
+		return os.Rename(path, target)
+	}
+
+	return fmt.Errorf("not found: %s", name)
+}
+
+// jsonDraftLine matches a draft flag inside JSON frontmatter
+var jsonDraftLine = regexp.MustCompile(`^(\s*)"draft"\s*:\s*true\s*(,?)\s*$`)
+
+// removeDraftFlag deletes any `draft:` line from the file's frontmatter. In
+// JSON frontmatter the flag is set to false instead, keeping commas valid.
+func removeDraftFlag(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if len(lines) == 0 {
+		return nil
+	}
+	delim := strings.TrimSpace(lines[0])
+	end := delim
+	switch delim {
+	case "---", "+++":
+	case "{":
+		end = "}"
+	default:
+		return nil // No frontmatter to edit
+	}
+
+	out := []string{lines[0]}
+	changed := false
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == end {
+			out = append(out, lines[i:]...)
+			break
+		}
+		if delim == "{" && jsonDraftLine.MatchString(lines[i]) {
+			out = append(out, jsonDraftLine.ReplaceAllString(lines[i], `$1"draft": false$2`))
+			changed = true
+			continue
+		}
+		if draftLine.MatchString(strings.TrimSpace(lines[i])) {
+			changed = true
+			continue
+		}
+		out = append(out, lines[i])
+	}
+
+	if !changed {
+		return nil
+	}
+	return os.WriteFile(path, []byte(strings.Join(out, "\n")), 0644)
+}
+
+// ListDraftPosts returns the names of all draft posts
+func ListDraftPosts() ([]string, error) {
+	return listDrafts(sourceDir("posts"), ".md")
+}
+
+// listSectionDrafts returns the drafts among the pages of the sections in
+// pagesDir, named <section>/<page> like published section pages
+func listSectionDrafts(pagesDir string, exts ...string) ([]string, error) {
+	var drafts []string
+	for _, dir := range []string{pagesDir, filepath.Join(pagesDir, draftsDirName)} {
+		sections, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		inDraftsDir := filepath.Base(dir) == draftsDirName
+		for _, section := range sections {
+			if !section.IsDir() || section.Name() == draftsDirName {
+				continue
+			}
+			files, err := os.ReadDir(filepath.Join(dir, section.Name()))
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				ext := filepath.Ext(file.Name())
+				if file.IsDir() || file.Name() == sectionIndexName || !hasExt(ext, exts) {
+					continue
+				}
+				if inDraftsDir || isDraftFile(filepath.Join(dir, section.Name(), file.Name())) {
+					drafts = append(drafts, section.Name()+"/"+strings.TrimSuffix(file.Name(), ext))
+				}
+			}
+		}
+	}
+	return drafts, nil
+}
+
+// ListDraftPages returns the names of all draft pages, including those of
+// sections
+func ListDraftPages() ([]string, error) {
+	pagesDir := sourceDir("pages")
+	drafts, err := listDrafts(pagesDir, ".md", ".html")
+	if err != nil {
+		return nil, err
+	}
+	sectionDrafts, err := listSectionDrafts(pagesDir, ".md", ".html")
+	if err != nil {
+		return nil, err
+	}
+	return append(drafts, sectionDrafts...), nil
+}
+
+// PublishPost makes a draft post part of the next build
+func PublishPost(title string) error {
+	if err := publish(sourceDir("posts"), title, ".md"); err != nil {
+		return fmt.Errorf("failed to publish post: %w", err)
+	}
+	return nil
+}
+
+// UnpublishPost turns a published post back into a draft
+func UnpublishPost(title string) error {
+	if err := unpublish(sourceDir("posts"), title, ".md"); err != nil {
+		return fmt.Errorf("failed to unpublish post: %w", err)
+	}
+	return nil
+}
+
+// PublishPage makes a draft page part of the next build
+func PublishPage(title string) error {
+	if err := publish(sourceDir("pages"), title, ".md", ".html"); err != nil {
+		return fmt.Errorf("failed to publish page: %w", err)
+	}
+	return nil
+}
+
+// UnpublishPage turns a published page back into a draft
+func UnpublishPage(title string) error {
+	if err := unpublish(sourceDir("pages"), title, ".md", ".html"); err != nil {
+		return fmt.Errorf("failed to unpublish page: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package generator
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var draftsSite = map[string]string{
+	"posts/live.md":         markdownFile("title: Live\ndate: 2024-01-02", "Live post"),
+	"posts/flagged.md":      markdownFile("title: Flagged\ndate: 2024-01-03\ndraft: true", "Flagged draft"),
+	"posts/drafts/moved.md": markdownFile("title: Moved\ndate: 2024-01-04", "Draft in drafts/"),
+	"pages/about.md":        markdownFile("title: About", "About page"),
+	"pages/secret.md":       markdownFile("title: Secret\ndraft: true", "Draft page"),
+}
+
+func TestBuildDrafts(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    BuildOptions
+		present []string
+		absent  []string
+	}{
+		{
+			name:    "published only",
+			present: []string{"posts/live.html", "pages/about.html"},
+			absent:  []string{"posts/flagged.html", "posts/moved.html", "pages/secret.html"},
+		},
+		{
+			name:    "with drafts",
+			opts:    BuildOptions{Drafts: true},
+			present: []string{"posts/live.html", "posts/flagged.html", "posts/moved.html", "pages/about.html", "pages/secret.html"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestSite(t, draftsSite)
+			buildTestSiteWith(t, tt.opts)
+
+			for _, file := range tt.present {
+				if !hasOutput(file) {
+					t.Errorf("%s was not built", file)
+				}
+			}
+			for _, file := range tt.absent {
+				if hasOutput(file) {
+					t.Errorf("%s was built", file)
+				}
+			}
+			if !tt.opts.Drafts {
+				assertNotContains(t, "index.html", "Flagged", "Moved")
+			}
+		})
+	}
+}
+
+func TestListDrafts(t *testing.T) {
+	newTestSite(t, draftsSite)
+
+	posts, err := ListDraftPosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(posts)
+	if want := []string{"flagged", "moved"}; !slices.Equal(posts, want) {
+		t.Errorf("ListDraftPosts() = %q, want %q", posts, want)
+	}
+
+	pages, err := ListDraftPages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"secret"}; !slices.Equal(pages, want) {
+		t.Errorf("ListDraftPages() = %q, want %q", pages, want)
+	}
+}
+
+func TestPublishAndUnpublish(t *testing.T) {
+	newTestSite(t, draftsSite)
+
+	// A draft in drafts/ moves back into posts/
+	if err := PublishPost("moved"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("posts/moved.md"); err != nil {
+		t.Errorf("published draft was not moved: %v", err)
+	}
+
+	// A flagged draft loses its flag and keeps the rest of its frontmatter
+	if err := PublishPost("flagged"); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile("posts/flagged.md")
+	if strings.Contains(string(content), "draft:") || !strings.Contains(string(content), "title: Flagged") {
+		t.Errorf("draft flag was not removed cleanly:\n%s", content)
+	}
+
+	// Unpublishing moves a post into drafts/
+	if err := UnpublishPost("live"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("posts/drafts/live.md"); err != nil {
+		t.Errorf("unpublished post was not moved to drafts/: %v", err)
+	}
+
+	buildTestSite(t)
+	for file, want := range map[string]bool{"posts/moved.html": true, "posts/flagged.html": true, "posts/live.html": false} {
+		if hasOutput(file) != want {
+			t.Errorf("%s built = %v, want %v", file, !want, want)
+		}
+	}
+
+	if err := UnpublishPost("missing"); err == nil {
+		t.Error("unpublishing a missing post succeeded")
+	}
+	if err := UnpublishPage("about"); err != nil {
+		t.Fatal(err)
+	}
+	if err := PublishPage("about"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("pages/about.md"); err != nil {
+		t.Errorf("page did not survive unpublish and publish: %v", err)
+	}
+}
+
+func TestUnpublishSectionPage(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"pages/docs/_index.md":  markdownFile("title: Docs", "All the docs"),
+		"pages/docs/install.md": markdownFile("title: Install", "Install it"),
+		"pages/docs/usage.md":   markdownFile("title: Usage\ndraft: true", "Use it"),
+		"pages/guides/intro.md": markdownFile("title: Intro", "Start here"),
+	})
+
+	for _, name := range []string{"docs/install", "guides/intro"} {
+		if err := UnpublishPage(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"pages/drafts/docs/install.md", "pages/drafts/guides/intro.md"} {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("unpublished section page is not in its section's drafts: %v", err)
+		}
+	}
+
+	drafts, err := ListDraftPages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(drafts)
+	if want := []string{"docs/install", "docs/usage", "guides/intro"}; !slices.Equal(drafts, want) {
+		t.Errorf("ListDraftPages() = %q, want %q", drafts, want)
+	}
+
+	// Previews show drafts in their sections, including sections without
+	// published pages
+	buildTestSiteWith(t, BuildOptions{Drafts: true})
+	assertContains(t, "pages/docs/install.html", "Install it")
+	assertContains(t, "pages/docs/index.html", ">Install<", ">Usage<")
+	assertContains(t, "pages/guides/intro.html", "Start here")
+	buildTestSite(t)
+	if hasOutput("pages/docs/install.html") || hasOutput("pages/guides/intro.html") {
+		t.Error("unpublished section pages were built")
+	}
+
+	if err := PublishPage("docs/install"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("pages/docs/install.md"); err != nil {
+		t.Errorf("published page did not return to its section: %v", err)
+	}
+	buildTestSite(t)
+	assertContains(t, "pages/docs/index.html", ">Install<")
+}
+
+func TestRemoveDraftFlag(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{"yaml", "---\ntitle: A\ndraft: true\n---\nbody\n", "---\ntitle: A\n---\nbody\n"},
+		{"toml", "+++\ntitle = \"A\"\ndraft = true\n+++\nbody\n", "+++\ntitle = \"A\"\n+++\nbody\n"},
+		{"body untouched", "---\ntitle: A\n---\ndraft: in the body\n", "---\ntitle: A\n---\ndraft: in the body\n"},
+		{"no frontmatter", "draft: true\n", "draft: true\n"},
+	}
+
+	t.Chdir(t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile("post.md", []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := removeDraftFlag("post.md"); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile("post.md")
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/yourusername/bazel_blog/internal/config"
+)
+
+const defaultFeedLanguage = "en-us"
+
+// feedData is shared by the RSS and Atom templates
+type feedData struct {
+	Config      *config.Config
+	Title       string
+	Link        string
+	FeedURL     string
+	Language    string
+	Author      config.AuthorConfig
+	Posts       []Post
+	FullContent bool // Include the full post next to its summary
+	BuildDate   time.Time
+}
+
+// generateFeeds writes the site wide RSS, Atom and JSON feeds
+func (s *Site) generateFeeds() error {
+	if err := s.writeRSS("feed.xml", s.Config.Title, s.Config.BaseURL, s.Posts); err != nil {
+		return err
+	}
+	if err := s.writeAtom("atom.xml", s.Config.Title, s.Config.BaseURL, s.Posts); err != nil {
+		return err
+	}
+	return s.writeJSONFeed("feed.json", s.Config.Title, s.Config.BaseURL, s.Posts)
+}
+
+// feedData collects what every feed format needs, applying the feed limit
+// and content settings from the config
+func (s *Site) feedData(outPath, title, link string, posts []Post) feedData {
+	if limit := s.Config.Feed.Limit; limit > 0 && len(posts) > limit {
+		posts = posts[:limit]
+	}
+
+	language := s.Config.Feed.Language
+	if language == "" {
+		language = defaultFeedLanguage
+	}
+
+	author := s.Config.Author
+	if author.Name == "" {
+		author.Name = s.Config.Title
+	}
+
+	// Feeds only change when a post does, so the newest post dates them
+	buildDate := time.Now()
+	if len(posts) > 0 {
+		buildDate = posts[0].Date
+	}
+
+	// Feed readers show the content away from the site, where relative
+	// links such as trip/photo.jpg no longer resolve
+	fullContent := s.Config.Feed.Content != "summary"
+	if fullContent {
+		items := make([]Post, len(posts))
+		for i, post := range posts {
+			post.Content = absoluteLinks(post.Content, post.Permalink)
+			items[i] = post
+		}
+		posts = items
+	}
+
+	return feedData{
+		Config:      s.Config,
+		Title:       title,
+		Link:        link,
+		FeedURL:     s.absURL(outPath),
+		Language:    language,
+		Author:      author,
+		Posts:       posts,
+		FullContent: fullContent,
+		BuildDate:   buildDate,
+	}
+}
+
+// linkAttr matches the src and href attributes of rendered HTML
+var linkAttr = regexp.MustCompile(`(\s)(src|href)="([^"]*)"`)
+
+// absoluteLinks resolves the relative src and href attributes in the HTML of
+// a post against its permalink. Content is left as is when the permalink is
+// not absolute, because base_url is not set.
+func absoluteLinks(content, permalink string) string {
+	base, err := url.Parse(permalink)
+	if err != nil || !base.IsAbs() {
+		return content
+	}
+	return linkAttr.ReplaceAllStringFunc(content, func(attr string) string {
+		m := linkAttr.FindStringSubmatch(attr)
+		ref, err := url.Parse(html.UnescapeString(m[3]))
+		if err != nil || ref.IsAbs() {
+			return attr
+		}
+		return m[1] + m[2] + `="` + html.EscapeString(base.ResolveReference(ref).String()) + `"`
+	})
+}
+
+// writeRSS writes an RSS 2.0 feed of posts to outPath, relative to public/
+func (s *Site) writeRSS(outPath, title, link string, posts []Post) error {
+	rssTemplate := `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+	<channel>
+		<title>{{xml .Title}}</title>
+		<description>{{xml .Config.Description}}</description>
+		<link>{{xml .Link}}</link>
+		<atom:link href="{{xml .FeedURL}}" rel="self" type="application/rss+xml" />
+		<language>{{xml .Language}}</language>
+		<lastBuildDate>{{.BuildDate.Format "Mon, 02 Jan 2006 15:04:05 -0700"}}</lastBuildDate>
+		<generator>Bazel Static Site Generator</generator>
+		{{- with .Author.Email}}
+		<managingEditor>{{xml .}}{{with $.Author.Name}} ({{xml .}}){{end}}</managingEditor>
+		{{- end}}
+		{{range .Posts}}
+		<item>
+			<title>{{xml .Title}}</title>
+			<description>{{xml .Summary}}</description>
+			{{- if $.FullContent}}
+			<content:encoded>{{xml .Content}}</content:encoded>
+			{{- end}}
+			<link>{{xml .Permalink}}</link>
+			<guid>{{xml .Permalink}}</guid>
+			<pubDate>{{.Date.Format "Mon, 02 Jan 2006 15:04:05 -0700"}}</pubDate>
+			{{- with .Author}}
+			<dc:creator>{{xml .}}</dc:creator>
+			{{- end}}
+			{{- with .ImageURL}}
+			<enclosure url="{{xml .}}" length="0" type="{{imageType .}}" />
+			{{- end}}
+			{{- range .Categories}}
+			<category>{{xml .}}</category>
+			{{- end}}
+		</item>
+		{{end}}
+	</channel>
+</rss>`
+
+	return s.writeFeedTemplate(outPath, rssTemplate, s.feedData(outPath, title, link, posts))
+}
+
+// writeAtom writes an Atom 1.0 feed of posts to outPath, relative to public/
+func (s *Site) writeAtom(outPath, title, link string, posts []Post) error {
+	atomTemplate := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="{{xml .Language}}">
+	<title>{{xml .Title}}</title>
+	<subtitle>{{xml .Config.Description}}</subtitle>
+	<link href="{{xml .Link}}" rel="alternate" type="text/html" />
+	<link href="{{xml .FeedURL}}" rel="self" type="application/atom+xml" />
+	<id>{{xml .Link}}</id>
+	<updated>{{.BuildDate.Format "2006-01-02T15:04:05Z07:00"}}</updated>
+	<author>
+		<name>{{xml .Author.Name}}</name>
+		{{- with .Author.Email}}
+		<email>{{xml .}}</email>
+		{{- end}}
+		{{- with .Author.URL}}
+		<uri>{{xml .}}</uri>
+		{{- end}}
+	</author>
+	<generator>Bazel Static Site Generator</generator>
+	{{range .Posts}}
+	<entry>
+		<title>{{xml .Title}}</title>
+		<link href="{{xml .Permalink}}" rel="alternate" type="text/html" />
+		<id>{{xml .Permalink}}</id>
+		<published>{{.Date.Format "2006-01-02T15:04:05Z07:00"}}</published>
+		<updated>{{if .Updated.IsZero}}{{.Date.Format "2006-01-02T15:04:05Z07:00"}}{{else}}{{.Updated.Format "2006-01-02T15:04:05Z07:00"}}{{end}}</updated>
+		{{- with .Author}}
+		<author>
+			<name>{{xml .}}</name>
+		</author>
+		{{- end}}
+		{{- range .Categories}}
+		<category term="{{xml .}}" />
+		{{- end}}
+		{{- range .Tags}}
+		<category term="{{xml .}}" />
+		{{- end}}
+		<summary>{{xml .Summary}}</summary>
+		{{- if $.FullContent}}
+		<content type="html" xml:base="{{xml .Permalink}}">{{xml .Content}}</content>
+		{{- end}}
+	</entry>
+	{{end}}
+</feed>`
+
+	return s.writeFeedTemplate(outPath, atomTemplate, s.feedData(outPath, title, link, posts))
+}
+
+// writeFeedTemplate renders an XML feed template to outPath
+func (s *Site) writeFeedTemplate(outPath, feedTemplate string, data feedData) error {
+	tmpl, err := texttemplate.New(outPath).Funcs(feedFuncs).Parse(feedTemplate)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	return s.writeOutput(outPath, buf.Bytes())
+}
+
+// jsonFeed is the top level object of a JSON Feed 1.1 document
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Authors     []jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Language      string           `json:"language,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+// writeJSONFeed writes a JSON Feed 1.1 document of posts to outPath,
+// relative to public/
+func (s *Site) writeJSONFeed(outPath, title, link string, posts []Post) error {
+	data := s.feedData(outPath, title, link, posts)
+
+	author := jsonFeedAuthor{Name: data.Author.Name, URL: data.Author.URL}
+	if author.URL == "" && data.Author.Email != "" {
+		author.URL = "mailto:" + data.Author.Email
+	}
+
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       data.Title,
+		HomePageURL: data.Link,
+		FeedURL:     data.FeedURL,
+		Description: s.Config.Description,
+		Language:    data.Language,
+		Authors:     []jsonFeedAuthor{author},
+		Items:       make([]jsonFeedItem, 0, len(data.Posts)),
+	}
+
+	for _, post := range data.Posts {
+		entry := jsonFeedItem{
+			ID:            post.Permalink,
+			URL:           post.Permalink,
+			Title:         post.Title,
+			Summary:       post.Summary,
+			Image:         post.ImageURL,
+			DatePublished: post.Date.Format(time.RFC3339),
+			Language:      post.Lang,
+			Tags:          append(append([]string{}, post.Categories...), post.Tags...),
+		}
+		if !post.Updated.IsZero() {
+			entry.DateModified = post.Updated.Format(time.RFC3339)
+		}
+		if post.Author != "" {
+			entry.Authors = []jsonFeedAuthor{{Name: post.Author}}
+		}
+		if data.FullContent {
+			entry.ContentHTML = post.Content
+		} else {
+			entry.ContentText = post.Summary
+		}
+		feed.Items = append(feed.Items, entry)
+	}
+
+	content, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return s.writeOutput(outPath, append(content, '\n'))
+}
+
+// feedFuncs are the functions available to feed templates. Feeds are XML, so
+// they use text/template and escape every value explicitly.
+var feedFuncs = texttemplate.FuncMap{
+	"xml": func(s string) string {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(s))
+		return buf.String()
+	},
+	"imageType": func(url string) string {
+		if t := mime.TypeByExtension(path.Ext(url)); t != "" {
+			return t
+		}
+		return "image/jpeg"
+	},
+}
@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+var feedSite = map[string]string{
+	"posts/first.md":  markdownFile("title: First & Best\ndate: 2024-01-02\ncategories: [notes]\ntags: [go]", "The **first** post"),
+	"posts/second.md": markdownFile("title: Second\ndate: 2024-02-03", "The second post"),
+	"posts/third.md":  markdownFile("title: Third\ndate: 2024-03-04", "The third post"),
+}
+
+// feedConfig is the config of feedSite with extra settings
+func feedConfig(extra string) string {
+	return "title = \"Feeds\"\nbase_url = \"https://example.com\"\n" + extra
+}
+
+type rssFeed struct {
+	Channel struct {
+		Title          string `xml:"title"`
+		Language       string `xml:"language"`
+		ManagingEditor string `xml:"managingEditor"`
+		Items          []struct {
+			Title       string   `xml:"title"`
+			Link        string   `xml:"link"`
+			Description string   `xml:"description"`
+			Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			Categories  []string `xml:"category"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomFeed struct {
+	Lang   string `xml:"lang,attr"`
+	Author struct {
+		Name  string `xml:"name"`
+		Email string `xml:"email"`
+	} `xml:"author"`
+	Entries []struct {
+		Title   string `xml:"title"`
+		ID      string `xml:"id"`
+		Content string `xml:"content"`
+		Summary string `xml:"summary"`
+	} `xml:"entry"`
+}
+
+func readXML(t *testing.T, name string, v interface{}) {
+	t.Helper()
+	if err := xml.Unmarshal([]byte(readOutput(t, name)), v); err != nil {
+		t.Fatalf("%s is not valid XML: %v", name, err)
+	}
+}
+
+func readJSONFeed(t *testing.T, name string) jsonFeed {
+	t.Helper()
+	var feed jsonFeed
+	if err := json.Unmarshal([]byte(readOutput(t, name)), &feed); err != nil {
+		t.Fatalf("%s is not valid JSON: %v", name, err)
+	}
+	return feed
+}
+
+func TestFeeds(t *testing.T) {
+	files := map[string]string{"bazel.toml": feedConfig("[author]\nname = \"Ada\"\nemail = \"ada@example.com\"\n")}
+	for name, content := range feedSite {
+		files[name] = content
+	}
+	newTestSite(t, files)
+	buildTestSite(t)
+
+	var rss rssFeed
+	readXML(t, "feed.xml", &rss)
+	if len(rss.Channel.Items) != 3 || rss.Channel.Items[0].Title != "Third" || rss.Channel.Items[2].Title != "First & Best" {
+		t.Errorf("RSS items = %+v, want the three posts newest first", rss.Channel.Items)
+	}
+	if first := rss.Channel.Items[2]; first.Link != "https://example.com/posts/first.html" ||
+		first.Description != "The first post" || !strings.Contains(first.Content, "<strong>first</strong>") ||
+		len(first.Categories) != 1 {
+		t.Errorf("RSS item = %+v", first)
+	}
+	if rss.Channel.Language != "en-us" || rss.Channel.ManagingEditor != "ada@example.com (Ada)" {
+		t.Errorf("RSS channel language %q, editor %q", rss.Channel.Language, rss.Channel.ManagingEditor)
+	}
+
+	var atom atomFeed
+	readXML(t, "atom.xml", &atom)
+	if len(atom.Entries) != 3 || atom.Entries[0].ID != "https://example.com/posts/third.html" {
+		t.Errorf("Atom entries = %+v", atom.Entries)
+	}
+	if atom.Author.Name != "Ada" || atom.Author.Email != "ada@example.com" {
+		t.Errorf("Atom author = %+v", atom.Author)
+	}
+	if !strings.Contains(atom.Entries[2].Content, "<strong>first</strong>") {
+		t.Errorf("Atom content = %q, want the full post", atom.Entries[2].Content)
+	}
+
+	feed := readJSONFeed(t, "feed.json")
+	if feed.Version != "https://jsonfeed.org/version/1.1" || feed.FeedURL != "https://example.com/feed.json" {
+		t.Errorf("JSON Feed = %+v", feed)
+	}
+	if len(feed.Items) != 3 || feed.Items[2].Title != "First & Best" || feed.Items[2].ContentHTML == "" {
+		t.Errorf("JSON Feed items = %+v", feed.Items)
+	}
+	if tags := feed.Items[2].Tags; len(tags) != 2 || tags[0] != "notes" || tags[1] != "go" {
+		t.Errorf("JSON Feed tags = %q, want categories then tags", tags)
+	}
+	if len(feed.Authors) != 1 || feed.Authors[0].URL != "mailto:ada@example.com" {
+		t.Errorf("JSON Feed authors = %+v", feed.Authors)
+	}
+}
+
+func TestFeedSettings(t *testing.T) {
+	files := map[string]string{"bazel.toml": feedConfig("[feed]\nlimit = 2\ncontent = \"summary\"\nlanguage = \"de\"\n")}
+	for name, content := range feedSite {
+		files[name] = content
+	}
+	newTestSite(t, files)
+	buildTestSite(t)
+
+	var rss rssFeed
+	readXML(t, "feed.xml", &rss)
+	if len(rss.Channel.Items) != 2 || rss.Channel.Language != "de" {
+		t.Errorf("RSS has %d items in %q, want 2 in de", len(rss.Channel.Items), rss.Channel.Language)
+	}
+	for _, item := range rss.Channel.Items {
+		if item.Content != "" {
+			t.Errorf("RSS item %q has full content in summary mode", item.Title)
+		}
+	}
+	// Without an author email there is no managing editor
+	if rss.Channel.ManagingEditor != "" {
+		t.Errorf("RSS managingEditor = %q without an author email", rss.Channel.ManagingEditor)
+	}
+
+	var atom atomFeed
+	readXML(t, "atom.xml", &atom)
+	if atom.Lang != "de" || atom.Author.Name != "Feeds" {
+		t.Errorf("Atom lang %q, author %q", atom.Lang, atom.Author.Name)
+	}
+	for _, entry := range atom.Entries {
+		if entry.Content != "" || strings.Contains(entry.Summary, "<") {
+			t.Errorf("Atom entry %q has content %q and summary %q, want a plain summary", entry.Title, entry.Content, entry.Summary)
+		}
+	}
+
+	feed := readJSONFeed(t, "feed.json")
+	if len(feed.Items) != 2 || feed.Items[0].ContentHTML != "" || feed.Items[0].ContentText != "The third post" {
+		t.Errorf("JSON Feed items = %+v, want two plain text summaries", feed.Items)
+	}
+}
+
+func TestFeedLinks(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":           feedConfig(""),
+		"posts/trip/index.md":  markdownFile("title: Trip\ndate: 2024-01-02", "![photo](photo.jpg) [about](../pages/about.html) [notes](#notes) [web](https://example.org/x) [mail](mailto:me@example.com) [search](/search?q=a&b=c)"),
+		"posts/trip/photo.jpg": "jpeg",
+		"pages/about.md":       markdownFile("title: About", "About"),
+	})
+	buildTestSite(t)
+
+	links := []string{
+		`src="https://example.com/posts/trip/photo.jpg"`,
+		`href="https://example.com/pages/about.html"`,
+		`href="https://example.com/posts/trip.html#notes"`,
+		`href="https://example.org/x"`,
+		`href="mailto:me@example.com"`,
+		`href="https://example.com/search?q=a&amp;b=c"`,
+	}
+
+	var rss rssFeed
+	readXML(t, "feed.xml", &rss)
+	feed := readJSONFeed(t, "feed.json")
+	if len(rss.Channel.Items) != 1 || len(feed.Items) != 1 {
+		t.Fatalf("feeds have %d and %d items, want 1", len(rss.Channel.Items), len(feed.Items))
+	}
+	for _, link := range links {
+		if !strings.Contains(rss.Channel.Items[0].Content, link) {
+			t.Errorf("RSS content does not contain %s:\n%s", link, rss.Channel.Items[0].Content)
+		}
+		if !strings.Contains(feed.Items[0].ContentHTML, link) {
+			t.Errorf("JSON Feed content does not contain %s:\n%s", link, feed.Items[0].ContentHTML)
+		}
+	}
+
+	// The post page itself keeps its relative links
+	assertContains(t, "posts/trip.html", `src="trip/photo.jpg"`, `href="../pages/about.html"`)
+}
@@ -0,0 +1,253 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrg/frontmatter"
+)
+
+// frontmatterError reports a problem at a line of a content file
+type frontmatterError struct {
+	File string
+	Line int // 1-based, zero when unknown
+	Msg  string
+}
+
+func (e *frontmatterError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Msg)
+}
+
+// joinDiagnostics joins the errors of loading several files, ordering the
+// frontmatter problems among them by file and then line. Other errors follow
+// in their original order.
+func joinDiagnostics(errs ...error) error {
+	var all []error
+	var flatten func(err error)
+	flatten = func(err error) {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				flatten(e)
+			}
+		} else if err != nil {
+			all = append(all, err)
+		}
+	}
+	for _, err := range errs {
+		flatten(err)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		a, aok := all[i].(*frontmatterError)
+		b, bok := all[j].(*frontmatterError)
+		switch {
+		case !aok || !bok:
+			return aok && !bok
+		case a.File != b.File:
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return errors.Join(all...)
+}
+
+// DateString is a frontmatter date kept as written. TOML frontmatter may use
+// native dates, which are converted to RFC 3339 text.
+type DateString string
+
+// UnmarshalTOML implements toml.Unmarshaler
+func (d *DateString) UnmarshalTOML(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		*d = DateString(v)
+	case time.Time:
+		*d = DateString(v.Format(time.RFC3339))
+	default:
+		return fmt.Errorf("expected a date, got %T", value)
+	}
+	return nil
+}
+
+// yamlErrorLine finds the line number in YAML and TOML decoder errors
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// parseFrontmatter decodes the YAML (---), TOML (+++) or JSON ({ }) frontmatter
+// of content into matter and returns the remaining body. In strict mode keys
+// that matter does not define are reported as errors too.
+func (s *Site) parseFrontmatter(path string, content []byte, matter interface{}) ([]byte, error) {
+	rest, err := frontmatter.Parse(bytes.NewReader(content), matter)
+	if err != nil {
+		return nil, parseError(path, content, err)
+	}
+	if !s.Options.Strict {
+		return rest, nil
+	}
+
+	var keys map[string]interface{}
+	if _, err := frontmatter.Parse(bytes.NewReader(content), &keys); err != nil {
+		return nil, parseError(path, content, err)
+	}
+
+	known := matterKeys(matter)
+	var unknown []string
+	for key := range keys {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	var errs []error
+	for _, key := range unknown {
+		errs = append(errs, &frontmatterError{path, keyLine(content, key), fmt.Sprintf("unknown frontmatter key %q", key)})
+	}
+	return rest, errors.Join(errs...)
+}
+
+// parseError turns a decoder error into a frontmatterError with the line in
+// the content file
+func parseError(path string, content []byte, err error) error {
+	start := frontmatterStart(content)
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// JSON frontmatter is decoded including its opening brace
+		return &frontmatterError{path, start + lineAt(content, start, syntaxErr.Offset), err.Error()}
+	case errors.As(err, &typeErr):
+		return &frontmatterError{path, start + lineAt(content, start, typeErr.Offset), err.Error()}
+	}
+
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &frontmatterError{path, start + line, err.Error()}
+	}
+	return &frontmatterError{path, start, err.Error()}
+}
+
+// frontmatterStart returns the 1-based line of the opening delimiter
+func frontmatterStart(content []byte) int {
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) != "" {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// lineAt returns how many lines past the start line the byte offset lies,
+// counting the offset from the start of that line
+func lineAt(content []byte, startLine int, offset int64) int {
+	lines := strings.SplitAfter(string(content), "\n")
+	begin := 0
+	for _, line := range lines[:startLine-1] {
+		begin += len(line)
+	}
+	end := begin + int(offset)
+	if end > len(content) {
+		end = len(content)
+	}
+	return strings.Count(string(content[begin:end]), "\n")
+}
+
+// keyLine returns the line a top-level frontmatter key is set on, or zero
+func keyLine(content []byte, key string) int {
+	pattern := regexp.MustCompile(`^\s*"?` + regexp.QuoteMeta(key) + `"?\s*[:=]`)
+	start := frontmatterStart(content)
+	lines := strings.Split(string(content), "\n")
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "---" || trimmed == "+++" || trimmed == "}" {
+			break
+		}
+		if pattern.MatchString(lines[i]) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// matterKeys returns the frontmatter keys a matter struct defines
+func matterKeys(matter interface{}) map[string]bool {
+	t := reflect.TypeOf(matter)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	keys := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// strictError reports an invalid value of key in strict mode
+func strictError(path string, content []byte, key, format string, args ...interface{}) error {
+	return &frontmatterError{path, keyLine(content, key), fmt.Sprintf(format, args...)}
+}
+
+// setFrontmatterValue sets a top-level frontmatter key to a literal value,
+// such as a number, in whichever format the file uses. Files without
+// frontmatter get a YAML block, and missing files are created with one.
+func setFrontmatterValue(path, key, value string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	start := frontmatterStart(content) - 1
+	delim := strings.TrimSpace(lines[start])
+	var line string
+	switch delim {
+	case "---":
+		line = key + ": " + value
+	case "+++":
+		line = key + " = " + value
+	case "{":
+		line = `  "` + key + `": ` + value + ","
+	default:
+		out := "---\n" + key + ": " + value + "\n---\n"
+		if len(bytes.TrimSpace(content)) > 0 {
+			out += "\n" + string(content)
+		}
+		return os.WriteFile(path, []byte(out), 0644)
+	}
+	isJSON := delim == "{"
+
+	if n := keyLine(content, key); n > 0 {
+		// Replace the current value, keeping the indentation and any comma
+		old := lines[n-1]
+		if isJSON {
+			indent := old[:len(old)-len(strings.TrimLeft(old, " \t"))]
+			line = indent + strings.TrimSpace(line)
+			if !strings.HasSuffix(strings.TrimSpace(old), ",") {
+				line = strings.TrimSuffix(line, ",")
+			}
+		}
+		lines[n-1] = line
+	} else {
+		// Add the key first, where it needs no comma only in an empty object
+		if isJSON && start+1 < len(lines) && strings.TrimSpace(lines[start+1]) == "}" {
+			line = strings.TrimSuffix(line, ",")
+		}
+		lines = append(lines[:start+1], append([]string{line}, lines[start+1:]...)...)
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseFrontmatterLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []int // Lines of the reported problems
+	}{
+		{"yaml valid", "---\ntitle: A\n---\nbody", nil},
+		{"yaml unknown key", "---\ntitle: A\nbogus: 1\n---\nbody", []int{3}},
+		{"yaml unknown keys", "---\nzeta: 1\ntitle: A\nalpha: 2\n---\nbody", []int{4, 2}},
+		{"yaml after blank lines", "\n\n---\ntitle: A\nbogus: 1\n---\nbody", []int{5}},
+		{"yaml syntax error", "---\ntitle: A\ntags: [a\ndate: 2024-01-01\n---\nbody", []int{3}},
+		{"yaml type error", "---\ntitle: A\ndraft: many\n---\nbody", []int{3}},
+		{"toml unknown key", "+++\ntitle = \"A\"\nbogus = 1\n+++\nbody", []int{3}},
+		{"toml syntax error", "+++\ntitle = \"A\"\ndraft = \n+++\nbody", []int{3}},
+		{"toml after blank line", "\n+++\ntitle = \"A\"\nbogus = 1\n+++\nbody", []int{4}},
+		{"json unknown key", "{\n  \"title\": \"A\",\n  \"bogus\": 1\n}\n\nbody", []int{3}},
+		{"json syntax error", "{\n  \"title\": \"A\"\n  \"draft\": true\n}\n\nbody", []int{3}},
+		{"json type error", "{\n  \"title\": \"A\",\n  \"draft\": \"yes\"\n}\n\nbody", []int{3}},
+		{"json after blank lines", "\n\n{\n  \"title\": \"A\",\n  \"draft\": \"yes\"\n}\n\nbody", []int{5}},
+	}
+
+	s := &Site{Options: BuildOptions{Strict: true}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matter PostMatter
+			_, err := s.parseFrontmatter("post.md", []byte(tt.content), &matter)
+
+			var got []int
+			for _, e := range flattenErrors(err) {
+				var fmErr *frontmatterError
+				if !errors.As(e, &fmErr) {
+					t.Fatalf("%v is not a frontmatterError", e)
+				}
+				got = append(got, fmErr.Line)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("problem lines = %v, want %v (%v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func flattenErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	if err == nil {
+		return nil
+	}
+	return []error{err}
+}
+
+func TestFrontmatterFormats(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/yaml.md": "---\ntitle: From YAML\ndate: 2024-01-01\ntags: [a]\n---\n\nYAML body\n",
+		"posts/toml.md": "+++\ntitle = \"From TOML\"\ndate = 2024-01-02T10:00:00Z\ntags = [\"b\"]\n+++\n\nTOML body\n",
+		"posts/json.md": "{\n  \"title\": \"From JSON\",\n  \"date\": \"2024-01-03\",\n  \"tags\": [\"c\"]\n}\n\nJSON body\n",
+		"pages/toml.md": "+++\ntitle = \"TOML Page\"\n+++\n\nPage body\n",
+	})
+	buildTestSite(t)
+
+	assertContains(t, "posts/yaml.html", "<h1>From YAML</h1>", "YAML body")
+	assertContains(t, "posts/toml.html", "<h1>From TOML</h1>", "January 2, 2024", "TOML body")
+	assertContains(t, "posts/json.html", "<h1>From JSON</h1>", "January 3, 2024", "JSON body")
+	assertContains(t, "pages/toml.html", "TOML Page", "Page body")
+	for _, tag := range []string{"a", "b", "c"} {
+		if !hasOutput("tags/" + tag + "/index.html") {
+			t.Errorf("tag %s has no page", tag)
+		}
+	}
+}
+
+func TestStrictBuild(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"unknown key", markdownFile("title: A\ndate: 2024-01-01\nbogus: 1", "body"), "posts/a.md:4: unknown frontmatter key"},
+		{"bad date", markdownFile("title: A\ndate: someday", "body"), "posts/a.md:3: cannot parse date"},
+		{"missing date", markdownFile("title: A", "body"), "posts/a.md:1: missing date"},
+		{"bad updated", markdownFile("title: A\ndate: 2024-01-01\nupdated: never", "body"), "posts/a.md:4: cannot parse updated date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestSite(t, map[string]string{"posts/a.md": tt.file})
+
+			// Outside strict mode the post is built with fallbacks
+			buildTestSite(t)
+
+			err := BuildSiteWithOptions(BuildOptions{Strict: true})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("strict build error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinDiagnostics(t *testing.T) {
+	other := errors.New("permalink conflict")
+	err := joinDiagnostics(
+		errors.Join(
+			&frontmatterError{"posts/b.md", 5, "bad date"},
+			&frontmatterError{"posts/b.md", 2, "unknown key"},
+		),
+		other,
+		errors.Join(&frontmatterError{"pages/a.md", 3, "bad date"}),
+		nil,
+	)
+
+	want := "pages/a.md:3: bad date\nposts/b.md:2: unknown key\nposts/b.md:5: bad date\npermalink conflict"
+	if err == nil || err.Error() != want {
+		t.Errorf("joinDiagnostics() =\n%v\nwant\n%s", err, want)
+	}
+	if joinDiagnostics(nil, nil) != nil {
+		t.Error("joinDiagnostics(nil, nil) is not nil")
+	}
+}
+
+func TestStrictBuildReportsEveryFile(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/b.md":     markdownFile("title: B\ndate: someday\nbogus: 1", "body"),
+		"posts/a.md":     markdownFile("title: A", "body"),
+		"pages/about.md": markdownFile("title: About\nbogus: 1", "body"),
+		"posts/ok.md":    markdownFile("title: OK\ndate: 2024-01-01", "body"),
+	})
+
+	err := BuildSiteWithOptions(BuildOptions{Strict: true})
+	want := `pages/about.md:3: unknown frontmatter key "bogus"
+posts/a.md:1: missing date
+posts/b.md:3: cannot parse date "someday"
+posts/b.md:4: unknown frontmatter key "bogus"`
+	if err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("strict build error =\n%v\nwant every problem ordered by file and line:\n%s", err, want)
+	}
+}
+
+func TestStrictDatesAgree(t *testing.T) {
+	dates := []string{"2024-01-02", "January 2, 2024", "2024-01-02T10:00:00+09:00", "1/2/2024", "2024-02-30", "someday"}
+	for _, date := range dates {
+		t.Run(date, func(t *testing.T) {
+			newTestSite(t, map[string]string{
+				"bazel.toml":     "title = \"Dates\"\ntimezone = \"UTC\"\n",
+				"posts/a.md":     markdownFile("title: A\ndate: "+date, "body"),
+				"pages/about.md": markdownFile("title: About\ndate: "+date, "body"),
+			})
+
+			err := BuildSiteWithOptions(BuildOptions{Strict: true})
+			if err == nil {
+				return
+			}
+			post := strings.Contains(err.Error(), "posts/a.md:3: cannot parse date")
+			page := strings.Contains(err.Error(), "pages/about.md:3: cannot parse date")
+			if post != page {
+				t.Errorf("posts and pages disagree on date %q: %v", date, err)
+			}
+			if !post {
+				t.Errorf("strict build error = %v", err)
+			}
+		})
+	}
+}
@@ -215,29 +215,48 @@ Happy blogging! 🎉`
 	return nil
 }
 
+// NewPost creates a post and opens it in the configured editor
 func NewPost(title string) error {
+	filename, err := CreatePost(title)
+	if err != nil {
+		return err
+	}
+
+	// Open in editor with enhanced error handling
+	err = openInEditor(filename)
+	if err != nil {
+		// Don't delete the file if editor fails - user can still access it
+		return fmt.Errorf("post created successfully but failed to open editor: %w", err)
+	}
+
+	return nil
+}
+
+// CreatePost writes a new post with frontmatter for the title and the
+// current date, and returns its path
+func CreatePost(title string) (string, error) {
 	// Validate post title
 	if strings.TrimSpace(title) == "" {
-		return fmt.Errorf("post title cannot be empty")
+		return "", fmt.Errorf("post title cannot be empty")
 	}
 
 	// Validate title length and characters
 	if len(title) > 100 {
-		return fmt.Errorf("post title too long (max 100 characters)")
+		return "", fmt.Errorf("post title too long (max 100 characters)")
 	}
 
 	// Check for invalid characters in title
 	invalidChars := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|"}
 	for _, char := range invalidChars {
 		if strings.Contains(title, char) {
-			return fmt.Errorf("post title contains invalid character: %s", char)
+			return "", fmt.Errorf("post title contains invalid character: %s", char)
 		}
 	}
 
 	// Create posts directory if it doesn't exist
-	postsDir := "posts"
+	postsDir := sourceDir("posts")
 	if err := os.MkdirAll(postsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create posts directory: %w", err)
+		return "", fmt.Errorf("failed to create posts directory: %w", err)
 	}
 
 	// Generate filename with better sanitization
@@ -250,28 +269,28 @@ func NewPost(title string) error {
 		return '_'
 	}, sanitizedTitle)
 
-	filename := fmt.Sprintf("posts/%s.md", sanitizedTitle)
+	filename := filepath.Join(postsDir, sanitizedTitle+".md")
 
 	// Check if post already exists
 	if _, err := os.Stat(filename); err == nil {
-		return fmt.Errorf("post already exists: %s", sanitizedTitle)
+		return "", fmt.Errorf("post already exists: %s", sanitizedTitle)
 	}
 
 	// Create the post file with enhanced error handling
 	postFile, err := os.Create(filename)
 	if err != nil {
 		if os.IsPermission(err) {
-			return fmt.Errorf("permission denied creating post file: %s", filename)
+			return "", fmt.Errorf("permission denied creating post file: %s", filename)
 		} else if os.IsExist(err) {
-			return fmt.Errorf("post file already exists: %s", filename)
+			return "", fmt.Errorf("post file already exists: %s", filename)
 		} else {
-			return fmt.Errorf("failed to create post file: %w", err)
+			return "", fmt.Errorf("failed to create post file: %w", err)
 		}
 	}
 	defer postFile.Close()
 
 	// Get current date and time
-	now := time.Now()
+	now := siteNow()
 	dateStr := now.Format("January 2, 2006")
 	timeStr := now.Format("15:04")
 
@@ -284,55 +303,80 @@ func NewPost(title string) error {
 		// Clean up the file if writing fails
 		postFile.Close()
 		os.Remove(filename)
-		return fmt.Errorf("failed to write post content: %w", err)
+		return "", fmt.Errorf("failed to write post content: %w", err)
 	}
 
 	// Ensure content is written to disk
 	if err := postFile.Sync(); err != nil {
-		return fmt.Errorf("failed to save post content: %w", err)
+		return "", fmt.Errorf("failed to save post content: %w", err)
 	}
 
-	// Close file before opening in editor
-	postFile.Close()
+	return filename, nil
+}
 
-	// Open in editor with enhanced error handling
-	err = openInEditor(filename)
+// NewPage creates a page and opens it in the configured editor
+func NewPage(title string) error {
+	filename, err := CreatePage(title)
 	if err != nil {
-		// Don't delete the file if editor fails - user can still access it
-		return fmt.Errorf("post created successfully but failed to open editor: %w", err)
+		return err
 	}
-
-	return nil
+	return openInEditor(filename)
 }
 
-func NewPage(title string) error {
+// CreatePage writes a new page with frontmatter for the title and the
+// current date, and returns its path
+func CreatePage(title string) (string, error) {
 	// Replace spaces with underscores for filename
-	filename := fmt.Sprintf("pages/%s.md", strings.ReplaceAll(title, " ", "_"))
+	filename := filepath.Join(sourceDir("pages"), strings.ReplaceAll(title, " ", "_")+".md")
 	if _, err := os.Stat(filename); err == nil {
-		return fmt.Errorf("page already exists")
+		return "", fmt.Errorf("page already exists")
 	}
 
 	pageFile, err := os.Create(filename)
 	if err != nil {
-		return fmt.Errorf("failed to create page: %w", err)
+		return "", fmt.Errorf("failed to create page: %w", err)
 	}
 	defer pageFile.Close()
 
 	// Get current date
-	now := time.Now()
+	now := siteNow()
 	dateStr := now.Format("January 2, 2006")
 
 	content := fmt.Sprintf("---\ntitle: %s\ndate: %s\n---\n\n# %s\n\nStart writing here...\n", title, dateStr, title)
 	_, err = pageFile.WriteString(content)
 	if err != nil {
-		return fmt.Errorf("failed to write page content: %w", err)
+		return "", fmt.Errorf("failed to write page content: %w", err)
 	}
 
-	return openInEditor(filename)
+	return filename, nil
+}
+
+// sourceDir returns the posts or pages directory of the site in the current
+// directory, which is under content_dir when the config sets one
+func sourceDir(kind string) string {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return kind // Fall back to the default layout if the config is broken
+	}
+	return filepath.Join(cfg.ContentDir, kind)
+}
+
+// siteNow returns the current time in the site's timezone, which new posts
+// and pages are dated in
+func siteNow() time.Time {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return time.Now()
+	}
+	loc, err := cfg.Location()
+	if err != nil {
+		return time.Now()
+	}
+	return time.Now().In(loc)
 }
 
 func ListPosts() ([]string, error) {
-	postsDir := "posts"
+	postsDir := sourceDir("posts")
 	if _, err := os.Stat(postsDir); os.IsNotExist(err) {
 		return []string{}, nil // No posts directory
 	}
@@ -344,9 +388,11 @@ func ListPosts() ([]string, error) {
 
 	var posts []string
 	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".md") {
+		if strings.HasSuffix(file.Name(), ".md") && !isDraftFile(filepath.Join(postsDir, file.Name())) {
 			postName := strings.TrimSuffix(file.Name(), ".md")
 			posts = append(posts, postName)
+		} else if index, ok := bundleIndexFile(filepath.Join(postsDir, file.Name())); ok && file.Name() != draftsDirName && !isDraftFile(index) {
+			posts = append(posts, file.Name())
 		}
 	}
 
@@ -354,7 +400,7 @@ func ListPosts() ([]string, error) {
 }
 
 func ListPages() ([]string, error) {
-	pagesDir := "pages"
+	pagesDir := sourceDir("pages")
 	if _, err := os.Stat(pagesDir); os.IsNotExist(err) {
 		return []string{}, nil // No pages directory
 	}
@@ -366,42 +412,65 @@ func ListPages() ([]string, error) {
 
 	var pages []string
 	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".md") {
-			pageName := strings.TrimSuffix(file.Name(), ".md")
-			pages = append(pages, pageName)
-		} else if strings.HasSuffix(file.Name(), ".html") {
-			pageName := strings.TrimSuffix(file.Name(), ".html")
-			pages = append(pages, pageName)
+		if file.IsDir() && file.Name() != draftsDirName {
+			// Pages in a section are named <section>/<page>
+			sectionFiles, err := os.ReadDir(filepath.Join(pagesDir, file.Name()))
+			if err != nil {
+				continue
+			}
+			for _, sectionFile := range sectionFiles {
+				if name, ok := pageName(filepath.Join(pagesDir, file.Name()), sectionFile); ok {
+					pages = append(pages, file.Name()+"/"+name)
+				}
+			}
+		} else if name, ok := pageName(pagesDir, file); ok {
+			pages = append(pages, name)
 		}
 	}
 
 	return pages, nil
 }
 
+// pageName returns the name of a published page file in dir
+func pageName(dir string, file os.DirEntry) (string, bool) {
+	switch {
+	case file.IsDir() || file.Name() == sectionIndexName:
+		return "", false
+	case strings.HasSuffix(file.Name(), ".md") && !isDraftFile(filepath.Join(dir, file.Name())):
+		return strings.TrimSuffix(file.Name(), ".md"), true
+	case strings.HasSuffix(file.Name(), ".html"):
+		return strings.TrimSuffix(file.Name(), ".html"), true
+	}
+	return "", false
+}
+
+// PostFile returns the source file of a post, which is the index.md of a
+// page bundle. Published posts take precedence over drafts with the same name.
+func PostFile(title string) (string, error) {
+	filename, err := findContentFile(sourceDir("posts"), title, ".md")
+	if err != nil {
+		return "", fmt.Errorf("post not found: %s", title)
+	}
+	return filename, nil
+}
+
 func EditPost(title string) error {
-	// Check for .md file first
-	filename := fmt.Sprintf("posts/%s.md", title)
-	if _, err := os.Stat(filename); err == nil {
-		return openInEditor(filename)
+	filename, err := PostFile(title)
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("post not found: %s", title)
+	return openInEditor(filename)
 }
 
 func EditPage(title string) error {
-	// Check for .md file first
-	filename := fmt.Sprintf("pages/%s.md", title)
-	if _, err := os.Stat(filename); err == nil {
-		return openInEditor(filename)
-	}
-
-	// Check for .html file
-	filename = fmt.Sprintf("pages/%s.html", title)
-	if _, err := os.Stat(filename); err == nil {
-		return openInEditor(filename)
+	// Check for .md file first, then .html, then the same in drafts
+	filename, err := findContentFile(sourceDir("pages"), title, ".md", ".html")
+	if err != nil {
+		return fmt.Errorf("page not found: %s", title)
 	}
 
-	return fmt.Errorf("page not found: %s", title)
+	return openInEditor(filename)
 }
 
 func openInEditor(filename string) error {
@@ -429,27 +498,59 @@ func openInEditor(filename string) error {
 }
 
 func DeletePost(title string) error {
-	// Check for .md file first
-	filename := fmt.Sprintf("posts/%s.md", title)
-	if _, err := os.Stat(filename); err == nil {
-		return os.Remove(filename)
+	filename, err := findContentFile(sourceDir("posts"), title, ".md")
+	if err != nil {
+		return fmt.Errorf("post not found: %s", title)
 	}
 
-	return fmt.Errorf("post not found: %s", title)
+	// Page bundles are deleted together with their assets
+	if filepath.Base(filename) == bundleIndex {
+		return os.RemoveAll(filepath.Dir(filename))
+	}
+	return os.Remove(filename)
 }
 
-func DeletePage(title string) error {
-	// Check for .md file first
-	filename := fmt.Sprintf("pages/%s.md", title)
-	if _, err := os.Stat(filename); err == nil {
-		return os.Remove(filename)
+// ResolvePost returns the name of the post ref refers to, which is either
+// a name as listed by ListPosts, a slug, or the post's URL or permalink.
+// Drafts and future posts are found too.
+func ResolvePost(ref string) (string, error) {
+	if _, err := findContentFile(sourceDir("posts"), ref, ".md"); err == nil {
+		return ref, nil
 	}
 
-	// Check for .html file
-	filename = fmt.Sprintf("pages/%s.html", title)
-	if _, err := os.Stat(filename); err == nil {
-		return os.Remove(filename)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	site, err := newSite(cfg, BuildOptions{Drafts: true, Future: true})
+	if err != nil {
+		return "", err
+	}
+	if err := site.loadPosts(); err != nil {
+		return "", err
+	}
+
+	var names []string
+	for _, post := range site.Posts {
+		if ref == post.Slug || strings.Trim(ref, "/") == strings.Trim(post.URL, "/") ||
+			strings.TrimSuffix(ref, "/") == strings.TrimSuffix(post.Permalink, "/") {
+			names = append(names, strings.TrimSuffix(strings.TrimSuffix(post.Filename, "/"+bundleIndex), ".md"))
+		}
+	}
+	switch len(names) {
+	case 0:
+		return "", fmt.Errorf("post not found: %s", ref)
+	case 1:
+		return names[0], nil
+	}
+	return "", fmt.Errorf("%s matches several posts: %s", ref, strings.Join(names, ", "))
+}
+
+func DeletePage(title string) error {
+	filename, err := findContentFile(sourceDir("pages"), title, ".md", ".html")
+	if err != nil {
+		return fmt.Errorf("page not found: %s", title)
 	}
 
-	return fmt.Errorf("page not found: %s", title)
+	return os.Remove(filename)
 }
@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yourusername/bazel_blog/internal/config"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+)
+
+// chromaStyles matches every color scheme with a code palette
+var chromaStyles = map[string]string{
+	"pika-beach":           "github",
+	"catppuccin-latte":     "catppuccin-latte",
+	"catppuccin-frappe":    "catppuccin-frappe",
+	"catppuccin-macchiato": "catppuccin-macchiato",
+	"catppuccin-mocha":     "catppuccin-mocha",
+	"dracula":              "dracula",
+	"nord":                 "nord",
+	"tokyo-night":          "tokyonight-night",
+	"3li7e":                "rrt",
+}
+
+// highlightStyle returns the chroma style for the site, preferring the
+// [highlight] style over the one matching the color scheme
+func highlightStyle(cfg *config.Config) string {
+	if cfg.Highlight.Style != "" {
+		return cfg.Highlight.Style
+	}
+	if style, ok := chromaStyles[cfg.Theme.ColorScheme]; ok {
+		return style
+	}
+	return chromaStyles["pika-beach"]
+}
+
+// highlightExtension highlights fenced code blocks with CSS classes, so the
+// palette lives in style.css and follows the color scheme. Fences accept
+// attributes such as ```go {linenos=table, hl_lines=[2, "4-6"]}.
+func highlightExtension(cfg *config.Config) goldmark.Extender {
+	return highlighting.NewHighlighting(
+		highlighting.WithStyle(highlightStyle(cfg)),
+		highlighting.WithFormatOptions(
+			chromahtml.WithClasses(true),
+			chromahtml.WithLineNumbers(cfg.Highlight.LineNumbers),
+		),
+	)
+}
+
+// highlightCSS returns the stylesheet for highlighted code
+func (s *Site) highlightCSS() string {
+	var buf bytes.Buffer
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	if err := formatter.WriteCSS(&buf, styles.Get(highlightStyle(s.Config))); err != nil {
+		return ""
+	}
+
+	// Drop the rule for the generic .bg class, which only standalone chroma
+	// pages use and which could clash with theme styles
+	var css strings.Builder
+	css.WriteString("\n/* Syntax highlighting */\n")
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if !strings.HasPrefix(line, "/* Background */") {
+			css.WriteString(line)
+		}
+	}
+	return css.String()
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/yourusername/bazel_blog/internal/config"
+)
+
+func TestHighlightStyle(t *testing.T) {
+	tests := []struct {
+		scheme, style, want string
+	}{
+		{scheme: "nord", want: "nord"},
+		{scheme: "tokyo-night", want: "tokyonight-night"},
+		{scheme: "nord", style: "monokai", want: "monokai"},
+		{scheme: "unknown", want: "github"},
+	}
+	for _, tt := range tests {
+		cfg := &config.Config{
+			Theme:     config.ThemeConfig{ColorScheme: tt.scheme},
+			Highlight: config.HighlightConfig{Style: tt.style},
+		}
+		if got := highlightStyle(cfg); got != tt.want {
+			t.Errorf("highlightStyle(%q, %q) = %q, want %q", tt.scheme, tt.style, got, tt.want)
+		}
+	}
+}
+
+const codePost = "```go\nfunc main() {}\n```"
+
+func TestHighlightBuild(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":    "title = \"Code\"\n",
+		"posts/code.md": markdownFile("title: Code\ndate: 2024-01-02", codePost),
+	})
+	buildTestSite(t)
+
+	assertContains(t, "posts/code.html", `<pre class="chroma">`, `<span class="kd">func</span>`)
+	assertNotContains(t, "posts/code.html", `style="`, `class="lnt"`)
+	assertContains(t, "style.css", "/* Syntax highlighting */", ".chroma")
+	assertNotContains(t, "style.css", "/* Background */")
+
+	// Changing the settings re-renders the cached markdown
+	writeSite(t, map[string]string{"bazel.toml": "title = \"Code\"\n[highlight]\nline_numbers = true\n"})
+	buildTestSite(t)
+	assertContains(t, "posts/code.html", `class="ln"`)
+}
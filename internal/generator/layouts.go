@@ -0,0 +1,480 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/yourusername/bazel_blog/internal/config"
+)
+
+// LayoutData is the value every layout is executed with
+type LayoutData struct {
+	Kind        string // "index", "post", "page", "section", ...
+	Title       string
+	Description string
+	Date        time.Time
+	Content     template.HTML
+	TOC         template.HTML // Table of contents of a post, when enabled
+	URL         string        // Path of the output file relative to public/
+	Permalink   string        // Absolute URL of the output file
+	Root        string        // Relative path from the output file back to public/
+	Config      *config.Config
+	Posts       []Post
+	Pages       []Page
+	Post        *Post
+	Page        *Page
+	Section     *Section
+	Menus       map[string][]MenuItem // Navigation menus by name, such as "main"
+	Taxonomy    *Taxonomy
+	Term        *Term
+	Paginator   *Paginator
+	Archive     *Archive
+	Archives    []*Archive
+}
+
+// layoutKinds lists the layouts rendered by BuildSite. Each kind is parsed
+// together with the base layout and the partials into its own template set.
+var layoutKinds = []string{"index", "post", "page", "section", "taxonomy", "term", "archive"}
+
+// builtinPartials are the partials available to every layout. A theme can
+// override any of them, or add new ones, in themes/<name>/layouts/partials/.
+var builtinPartials = map[string]string{
+	"head":   headPartial,
+	"header": headerPartial,
+	"footer": footerPartial,
+	"math":   mathPartial,
+}
+
+// builtinLayouts are used whenever the theme does not provide its own file
+var builtinLayouts = map[string]string{
+	"base":     baseLayout,
+	"index":    indexLayout,
+	"post":     postLayout,
+	"page":     pageLayout,
+	"section":  sectionLayout,
+	"taxonomy": taxonomyLayout,
+	"term":     termLayout,
+	"archive":  archiveLayout,
+}
+
+// layoutFuncs returns the functions available to every layout
+func layoutFuncs() template.FuncMap {
+	funcs := sprig.FuncMap()
+	funcs["slugify"] = slugify
+	return funcs
+}
+
+// themeLayoutDir returns the directory user layouts are loaded from
+func (s *Site) themeLayoutDir() string {
+	return filepath.Join(s.themeDir(), "layouts")
+}
+
+// loadLayouts parses the layouts for every page kind, preferring files from
+// the active theme and falling back to the built-in templates.
+func (s *Site) loadLayouts() error {
+	dir := s.themeLayoutDir()
+
+	partials := make(map[string]string, len(builtinPartials))
+	for name, src := range builtinPartials {
+		partials[name] = src
+	}
+
+	// Theme partials override built-ins with the same name
+	partialFiles, _ := filepath.Glob(filepath.Join(dir, "partials", "*.html"))
+	for _, path := range partialFiles {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read partial %s: %w", path, err)
+		}
+		partials[strings.TrimSuffix(filepath.Base(path), ".html")] = string(content)
+	}
+
+	base, err := readLayout(dir, "base")
+	if err != nil {
+		return err
+	}
+
+	// Hash every layout source so the build cache notices template changes
+	names := make([]string, 0, len(partials))
+	for name := range partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sources := []string{base}
+	for _, name := range names {
+		sources = append(sources, name, partials[name])
+	}
+
+	s.layouts = make(map[string]*template.Template, len(layoutKinds))
+	for _, kind := range layoutKinds {
+		src, err := readLayout(dir, kind)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, kind, src)
+
+		tmpl := template.New(kind).Funcs(layoutFuncs())
+		if _, err := tmpl.New("base").Parse(base); err != nil {
+			return fmt.Errorf("failed to parse base layout: %w", err)
+		}
+		for name, partial := range partials {
+			if _, err := tmpl.New(name).Parse(partial); err != nil {
+				return fmt.Errorf("failed to parse partial %s: %w", name, err)
+			}
+		}
+		if _, err := tmpl.Parse(src); err != nil {
+			return fmt.Errorf("failed to parse %s layout: %w", kind, err)
+		}
+
+		s.layouts[kind] = tmpl
+	}
+	s.layoutHash = hashStrings(sources...)
+
+	return nil
+}
+
+// readLayout returns the theme's version of a layout, or the built-in one
+// when the theme does not provide it.
+func readLayout(dir, name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, name+".html"))
+	if os.IsNotExist(err) {
+		return builtinLayouts[name], nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s layout: %w", name, err)
+	}
+	return string(content), nil
+}
+
+// renderLayout executes the layout for kind and writes it to outPath,
+// which is relative to the public/ directory.
+func (s *Site) renderLayout(kind, outPath string, data LayoutData) error {
+	data.Kind = kind
+	data.Config = s.Config
+	data.Pages = s.Pages
+	data.Menus = s.Menus
+	data.Root = relRoot(outPath)
+	data.Archives = s.Archives
+	if data.Posts == nil {
+		data.Posts = s.Posts
+	}
+
+	s.addToSitemap(data)
+
+	// Posts and pages whose inputs are unchanged since the last build are kept
+	key := s.renderKey(kind, data)
+	if s.upToDate(outPath, key) {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := s.layouts[kind].ExecuteTemplate(&buf, "base", data); err != nil {
+		return err
+	}
+	return s.writeRendered(outPath, buf.Bytes(), key)
+}
+
+// relRoot returns the relative path from an output file back to public/
+func relRoot(outPath string) string {
+	depth := strings.Count(filepath.ToSlash(outPath), "/")
+	if depth == 0 {
+		return "./"
+	}
+	return strings.Repeat("../", depth)
+}
+
+// absURL joins a path relative to public/ onto the site's base URL
+func (s *Site) absURL(path string) string {
+	return strings.TrimSuffix(s.Config.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+const baseLayout = `<!DOCTYPE html>
+<html lang="{{with .Post}}{{with .Lang}}{{.}}{{else}}en{{end}}{{else}}en{{end}}">
+<head>
+    {{template "head" .}}
+</head>
+<body class="site-view">
+    {{template "header" .}}
+
+    <main class="site-main">
+        {{template "main" .}}
+    </main>
+
+    {{template "footer" .}}
+</body>
+</html>`
+
+const headPartial = `<meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{if eq .Kind "index"}}{{.Config.Title}}{{else}}{{.Title}} - {{.Config.Title}}{{end}}</title>
+    <meta name="description" content="{{.Description}}">
+
+    <!-- Open Graph / Facebook -->
+    <meta property="og:type" content="{{if eq .Kind "post"}}article{{else}}website{{end}}">
+    <meta property="og:url" content="{{.Permalink}}">
+    <meta property="og:title" content="{{if eq .Kind "index"}}{{.Config.Title}}{{else}}{{.Title}}{{end}}">
+    <meta property="og:description" content="{{.Description}}">
+    <meta property="og:site_name" content="{{.Config.Title}}">
+    {{- with .Post}}
+    <meta property="article:published_time" content="{{.Date.Format "2006-01-02T15:04:05Z07:00"}}">
+    {{- if not .Updated.IsZero}}
+    <meta property="article:modified_time" content="{{.Updated.Format "2006-01-02T15:04:05Z07:00"}}">
+    {{- end}}
+    <meta property="article:author" content="{{with .Author}}{{.}}{{else}}{{$.Config.Title}}{{end}}">
+    {{- with .ImageURL}}
+    <meta property="og:image" content="{{.}}">
+    {{- end}}
+    {{- end}}
+
+    <!-- X (Twitter) -->
+    <meta property="twitter:card" content="{{if and .Post .Post.ImageURL}}summary_large_image{{else}}summary{{end}}">
+    <meta property="twitter:url" content="{{.Permalink}}">
+    <meta property="twitter:title" content="{{if eq .Kind "index"}}{{.Config.Title}}{{else}}{{.Title}}{{end}}">
+    <meta property="twitter:description" content="{{.Description}}">
+    {{- if and .Post .Post.ImageURL}}
+    <meta property="twitter:image" content="{{.Post.ImageURL}}">
+    {{- end}}
+
+    <!-- Additional SEO -->
+    <link rel="canonical" href="{{.Permalink}}">
+
+    <link rel="stylesheet" href="{{.Root}}style.css">
+    <link rel="alternate" type="application/rss+xml" title="RSS Feed" href="{{.Root}}feed.xml">
+    <link rel="alternate" type="application/atom+xml" title="Atom Feed" href="{{.Root}}atom.xml">
+    <link rel="alternate" type="application/feed+json" title="JSON Feed" href="{{.Root}}feed.json">
+    {{- with .Term}}
+    <link rel="alternate" type="application/rss+xml" title="{{.Name}} RSS Feed" href="feed.xml">
+    {{- end}}
+    {{- if .Config.Markdown.Math}}
+    {{template "math" .}}
+    {{- end}}`
+
+// mathPartial loads KaTeX to typeset the \( \) and \[ \] math written by the
+// markdown renderer when markdown.math is set
+const mathPartial = `<link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/katex@0.16.11/dist/katex.min.css" crossorigin="anonymous">
+    <script defer src="https://cdn.jsdelivr.net/npm/katex@0.16.11/dist/katex.min.js" crossorigin="anonymous"></script>
+    <script defer src="https://cdn.jsdelivr.net/npm/katex@0.16.11/dist/contrib/auto-render.min.js" crossorigin="anonymous"
+        onload="renderMathInElement(document.body, {delimiters: [{left: '\\[', right: '\\]', display: true}, {left: '\\(', right: '\\)', display: false}]})"></script>`
+
+const headerPartial = `<header class="site-header">
+        <div>
+            <h2><a href="{{.Root}}">{{.Config.Title}}</a></h2>
+            <nav class="site-nav">
+                {{- if ne .Kind "index"}}
+                <a href="{{.Root}}">Home</a>
+                {{- end}}
+                {{- range .Menus.main}}
+                <a href="{{if not .External}}{{$.Root}}{{end}}{{.URL}}"{{if eq .URL $.URL}} aria-current="page"{{end}}>{{.Name}}</a>
+                {{- end}}
+            </nav>
+        </div>
+    </header>`
+
+const footerPartial = `<footer class="site-footer">
+        {{- with .Menus.footer}}
+        <nav class="footer-nav">
+            {{- range .}}
+            <a href="{{if not .External}}{{$.Root}}{{end}}{{.URL}}">{{.Name}}</a>
+            {{- end}}
+        </nav>
+        {{- end}}
+        <p>
+            Made with <strong>BazelBlog</strong>
+            {{if .Config.Socials}}
+            | Connect with us:
+            {{range $platform, $url := .Config.Socials}}
+            <a href="{{$url}}">{{$platform}}</a>
+            {{end}}
+            {{end}}
+        </p>
+    </footer>`
+
+const indexLayout = `{{define "main"}}
+        {{if .Config.Description}}
+        <div style="margin-bottom: 2rem;">
+            <p>{{.Config.Description}}</p>
+        </div>
+        {{end}}
+
+        <hr>
+
+        {{if .Posts}}
+        {{$currentYear := 0}}
+        {{range .Posts}}
+        {{$postYear := .Date.Year}}
+        {{if ne $postYear $currentYear}}
+        {{if ne $currentYear 0}}
+        </ul>
+        {{end}}
+        <h2><a href="{{$.Root}}archive/{{$postYear}}/">{{$postYear}}</a></h2>
+        <ul class="site-list-of-posts">
+        {{$currentYear = $postYear}}
+        {{end}}
+            <li>
+                <time>{{.Date.Format "2 Jan"}}</time>
+                <div class="post-link">
+                    <a href="{{$.Root}}{{.URL}}">{{.Title}}</a>
+                    {{- if .Summary}}
+                    <p class="post-summary">{{.Summary}} <span class="reading-time">· {{.ReadingTime}} min read</span></p>
+                    {{- end}}
+                </div>
+            </li>
+        {{end}}
+        </ul>
+        {{end}}
+
+        {{- with .Paginator}}{{if gt .TotalPages 1}}
+        <nav class="pagination">
+            {{if .PrevURL}}<a href="{{$.Root}}{{.PrevURL}}">&larr; Newer posts</a>{{else}}<span></span>{{end}}
+            <span>Page {{.PageNumber}} of {{.TotalPages}}</span>
+            {{if .NextURL}}<a href="{{$.Root}}{{.NextURL}}">Older posts &rarr;</a>{{else}}<span></span>{{end}}
+        </nav>
+        {{- end}}{{end}}
+{{end}}`
+
+const postLayout = `{{define "main"}}
+        <h1>{{.Title}}</h1>
+        <div class="post-date">
+            {{- .Date.Format "January 2, 2006"}}
+            {{- with .Post.Author}} · {{.}}{{end}}
+            {{- if not .Post.Updated.IsZero}} · Updated {{.Post.Updated.Format "January 2, 2006"}}{{end}}
+            {{- ""}} · {{.Post.ReadingTime}} min read
+            {{- if .Post.Draft}} · Draft{{end -}}
+        </div>
+        {{- with .Post}}{{if .ImageURL}}
+        <img class="post-cover" src="{{if .ImagePath}}{{$.Root}}{{.ImagePath}}{{else}}{{.ImageURL}}{{end}}" alt="">
+        {{- end}}{{end}}
+        {{- with .Post.SeriesNav}}
+        <aside class="series">
+            <p class="series-title">Part {{.Part}} of {{.Total}} in <a href="{{$.Root}}{{.Term.URL}}">{{.Term.Name}}</a></p>
+            <ol>
+                {{- range $i, $part := .Term.Posts}}
+                <li>{{if eq (add1 $i) $.Post.SeriesNav.Part}}<strong>{{$part.Title}}</strong>{{else}}<a href="{{$.Root}}{{$part.URL}}">{{$part.Title}}</a>{{end}}</li>
+                {{- end}}
+            </ol>
+        </aside>
+        {{- end}}
+        {{- with .TOC}}
+        {{.}}
+        {{- end}}
+        <div class="post-content">
+            {{.Content}}
+        </div>
+        {{- with .Post.SeriesNav}}{{if or .Prev .Next}}
+        <nav class="series-nav">
+            {{- with .Prev}}
+            <a class="prev" href="{{$.Root}}{{.URL}}">← {{.Title}}</a>
+            {{- end}}
+            {{- with .Next}}
+            <a class="next" href="{{$.Root}}{{.URL}}">{{.Title}} →</a>
+            {{- end}}
+        </nav>
+        {{- end}}{{end}}
+        {{- if or .Post.Tags .Post.Categories}}
+        <div class="post-terms">
+            {{- range .Post.Categories}}
+            <a href="{{$.Root}}categories/{{slugify .}}/">{{.}}</a>
+            {{- end}}
+            {{- range .Post.Tags}}
+            <a href="{{$.Root}}tags/{{slugify .}}/">#{{.}}</a>
+            {{- end}}
+        </div>
+        {{- end}}
+{{end}}`
+
+const pageLayout = `{{define "main"}}
+        {{.Content}}
+{{end}}`
+
+const sectionLayout = `{{define "main"}}
+        {{- if .Content}}
+        {{.Content}}
+        {{- else}}
+        <h1>{{.Section.Title}}</h1>
+        {{- end}}
+        <ul class="site-list-of-terms">
+            {{- range .Section.Pages}}
+            <li><a href="{{$.Root}}{{.URL}}">{{.Title}}</a></li>
+            {{- end}}
+        </ul>
+{{end}}`
+
+const taxonomyLayout = `{{define "main"}}
+        <h1>{{.Taxonomy.Title}}</h1>
+        <ul class="site-list-of-terms">
+            {{- range .Taxonomy.Terms}}
+            <li><a href="{{$.Root}}{{.URL}}">{{.Name}}</a> <span class="count">({{len .Posts}})</span></li>
+            {{- end}}
+        </ul>
+{{end}}`
+
+const termLayout = `{{define "main"}}
+        <h1>{{.Term.Name}}</h1>
+        <p class="post-date">{{len .Posts}} post{{if ne (len .Posts) 1}}s{{end}} in this {{.Taxonomy.Singular}} · <a href="feed.xml">RSS</a> · <a href="{{.Root}}{{.Taxonomy.URL}}">All {{.Taxonomy.Name}}</a></p>
+        <ul class="site-list-of-posts">
+            {{- range .Posts}}
+            <li>
+                <time>{{.Date.Format "2 Jan"}}</time>
+                <div class="post-link">
+                    <a href="{{$.Root}}{{.URL}}">{{.Title}}</a>
+                    {{- if .Summary}}
+                    <p class="post-summary">{{.Summary}} <span class="reading-time">· {{.ReadingTime}} min read</span></p>
+                    {{- end}}
+                </div>
+            </li>
+            {{- end}}
+        </ul>
+{{end}}`
+
+const archiveLayout = `{{define "main"}}
+        <h1>{{if .Archive}}{{.Archive.Title}}{{else}}Archive{{end}}</h1>
+        {{- if not .Archive}}
+        {{- range .Archives}}
+        <h2><a href="{{$.Root}}{{.URL}}">{{.Title}}</a></h2>
+        <ul class="site-list-of-terms">
+            {{- range .Months}}
+            <li><a href="{{$.Root}}{{.URL}}">{{.Month}}</a> <span class="count">({{len .Posts}})</span></li>
+            {{- end}}
+        </ul>
+        {{- end}}
+        {{- else if .Archive.Months}}
+        {{- range .Archive.Months}}
+        <h2><a href="{{$.Root}}{{.URL}}">{{.Month}}</a></h2>
+        <ul class="site-list-of-posts">
+            {{- range .Posts}}
+            <li>
+                <time>{{.Date.Format "2 Jan"}}</time>
+                <div class="post-link">
+                    <a href="{{$.Root}}{{.URL}}">{{.Title}}</a>
+                    {{- if .Summary}}
+                    <p class="post-summary">{{.Summary}} <span class="reading-time">· {{.ReadingTime}} min read</span></p>
+                    {{- end}}
+                </div>
+            </li>
+            {{- end}}
+        </ul>
+        {{- end}}
+        {{- else}}
+        <ul class="site-list-of-posts">
+            {{- range .Posts}}
+            <li>
+                <time>{{.Date.Format "2 Jan"}}</time>
+                <div class="post-link">
+                    <a href="{{$.Root}}{{.URL}}">{{.Title}}</a>
+                    {{- if .Summary}}
+                    <p class="post-summary">{{.Summary}} <span class="reading-time">· {{.ReadingTime}} min read</span></p>
+                    {{- end}}
+                </div>
+            </li>
+            {{- end}}
+        </ul>
+        <p class="post-date"><a href="{{.Root}}archive/{{.Archive.Year}}/">All of {{.Archive.Year}}</a></p>
+        {{- end}}
+{{end}}`
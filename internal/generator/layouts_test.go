@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThemeLayouts(t *testing.T) {
+	hello := markdownFile("title: Hello\ndate: 2024-01-02", "Hello body")
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		file    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "built-in layouts",
+			files: map[string]string{"posts/hello.md": hello},
+			file:  "posts/hello.html",
+			want:  []string{"<h1>Hello</h1>", "Hello body", `href="../style.css"`},
+		},
+		{
+			name: "theme layout",
+			files: map[string]string{
+				"posts/hello.md":                   hello,
+				"themes/default/layouts/post.html": `{{define "main"}}<article class="custom">{{.Title}}: {{.Content}}</article>{{end}}`,
+			},
+			file: "posts/hello.html",
+			want: []string{`<article class="custom">Hello: <p>Hello body</p>`, "<footer"},
+		},
+		{
+			name: "theme partial",
+			files: map[string]string{
+				"posts/hello.md": hello,
+				"themes/default/layouts/partials/footer.html": `<footer>Custom footer on {{.Kind}}</footer>`,
+			},
+			file: "posts/hello.html",
+			want: []string{"Custom footer on post", "<h1>Hello</h1>"},
+		},
+		{
+			name: "named theme",
+			files: map[string]string{
+				"bazel.toml":                        "[theme]\nname = \"minimal\"\n",
+				"posts/hello.md":                    hello,
+				"themes/default/layouts/index.html": `{{define "main"}}default theme{{end}}`,
+				"themes/minimal/layouts/index.html": `{{define "main"}}minimal theme{{range .Posts}} {{.Title}}{{end}}{{end}}`,
+			},
+			file: "index.html",
+			want: []string{"minimal theme Hello"},
+		},
+		{
+			name: "sprig functions",
+			files: map[string]string{
+				"posts/hello.md":                   hello,
+				"themes/default/layouts/post.html": `{{define "main"}}{{.Title | upper}}{{end}}`,
+			},
+			file: "posts/hello.html",
+			want: []string{"HELLO"},
+		},
+		{
+			name: "invalid layout",
+			files: map[string]string{
+				"themes/default/layouts/post.html": `{{define "main"}}{{.Title}{{end}}`,
+			},
+			wantErr: "failed to parse post layout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestSite(t, tt.files)
+
+			err := BuildSite()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertContains(t, tt.file, tt.want...)
+		})
+	}
+}
+
+func TestRelRoot(t *testing.T) {
+	tests := map[string]string{
+		"index.html":         "./",
+		"posts/hello.html":   "../",
+		"tags/go/index.html": "../../",
+		"a/b/c/d/index.html": "../../../../",
+	}
+	for outPath, want := range tests {
+		if got := relRoot(outPath); got != want {
+			t.Errorf("relRoot(%q) = %q, want %q", outPath, got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/frontmatter"
+)
+
+// ContentInfo describes a post or page for `bazel post list` and `bazel page list`
+type ContentInfo struct {
+	Name  string `json:"name"` // Name accepted by edit, delete and publish
+	Title string `json:"title"`
+	Date  string `json:"date,omitempty"` // As written in the frontmatter
+	File  string `json:"file"`
+	Draft bool   `json:"draft"`
+}
+
+// ListPostInfo describes the published posts, or the drafts when drafts is set
+func ListPostInfo(drafts bool) ([]ContentInfo, error) {
+	list := ListPosts
+	if drafts {
+		list = ListDraftPosts
+	}
+	names, err := list()
+	if err != nil {
+		return nil, err
+	}
+	return describeContent(sourceDir("posts"), names, drafts, ".md"), nil
+}
+
+// ListPageInfo describes the published pages, or the drafts when drafts is set
+func ListPageInfo(drafts bool) ([]ContentInfo, error) {
+	list := ListPages
+	if drafts {
+		list = ListDraftPages
+	}
+	names, err := list()
+	if err != nil {
+		return nil, err
+	}
+	return describeContent(sourceDir("pages"), names, drafts, ".md", ".html"), nil
+}
+
+// describeContent reads the title and date of each named file in contentDir
+func describeContent(contentDir string, names []string, drafts bool, exts ...string) []ContentInfo {
+	infos := make([]ContentInfo, 0, len(names))
+	for _, name := range names {
+		path, err := findContentFile(contentDir, name, exts...)
+		if err != nil {
+			continue
+		}
+		info := ContentInfo{
+			Name:  name,
+			Title: defaultTitle(filepath.Base(name)),
+			File:  filepath.ToSlash(path),
+			Draft: drafts,
+		}
+
+		if strings.HasSuffix(path, ".md") {
+			var matter struct {
+				Title string     `yaml:"title" toml:"title" json:"title"`
+				Date  DateString `yaml:"date" toml:"date" json:"date"`
+			}
+			content, err := os.ReadFile(path)
+			if err == nil {
+				if _, err := frontmatter.Parse(bytes.NewReader(content), &matter); err == nil {
+					if matter.Title != "" {
+						info.Title = matter.Title
+					}
+					info.Date = string(matter.Date)
+				}
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/bazel_blog/internal/config"
+)
+
+// renderMarkdown converts markdown with the given [markdown] settings
+func renderMarkdown(t *testing.T, opts config.MarkdownConfig, markdown string) string {
+	t.Helper()
+	s := newTestRenderer(t)
+	s.Config.Markdown = opts
+	s.markdown = newMarkdown(s.Config, s.shortcodes)
+	return s.markdownToHTML(markdown, nil).HTML
+}
+
+func TestMarkdownOptions(t *testing.T) {
+	off := false
+	tests := []struct {
+		name     string
+		opts     config.MarkdownConfig
+		markdown string
+		want     []string
+		unwanted []string
+	}{
+		{
+			name:     "defaults",
+			markdown: "one\ntwo <kbd>x</kbd> [^1] \"quoted\"\n\n[^1]: Note\n\nTerm\n: Definition",
+			want:     []string{"one<br />\ntwo", "<kbd>x</kbd>", "&quot;quoted&quot;"},
+			unwanted: []string{"footnote", "<dl>"},
+		},
+		{
+			name:     "footnotes",
+			opts:     config.MarkdownConfig{Footnotes: true},
+			markdown: "Text[^1]\n\n[^1]: The note",
+			want:     []string{`<sup id="fnref:1">`, `<div class="footnotes" role="doc-endnotes">`, "The note"},
+		},
+		{
+			name:     "definition lists",
+			opts:     config.MarkdownConfig{DefinitionLists: true},
+			markdown: "Term\n: Definition",
+			want:     []string{"<dl>\n<dt>Term</dt>\n<dd>Definition</dd>\n</dl>"},
+		},
+		{
+			name:     "typographer",
+			opts:     config.MarkdownConfig{Typographer: true},
+			markdown: `"Quoted" -- and... more`,
+			want:     []string{"&ldquo;Quoted&rdquo; &ndash; and&hellip; more"},
+		},
+		{
+			name:     "no hard wraps",
+			opts:     config.MarkdownConfig{HardWraps: &off},
+			markdown: "one\ntwo",
+			want:     []string{"<p>one\ntwo</p>"},
+		},
+		{
+			name:     "no raw html",
+			opts:     config.MarkdownConfig{Unsafe: &off},
+			markdown: "a <kbd>x</kbd>\n\n<div>block</div>",
+			want:     []string{"<!-- raw HTML omitted -->"},
+			unwanted: []string{"<kbd>", "<div>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := renderMarkdown(t, tt.opts, tt.markdown)
+			for _, want := range tt.want {
+				if !strings.Contains(html, want) {
+					t.Errorf("output does not contain %q:\n%s", want, html)
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(html, unwanted) {
+					t.Errorf("output contains %q:\n%s", unwanted, html)
+				}
+			}
+		})
+	}
+}
+
+func TestMath(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"inline", "Euler: $e^{i\\pi} + 1 = 0$.", `<p>Euler: <span class="math inline">\(e^{i\pi} + 1 = 0\)</span>.</p>`},
+		{"underscores", "$a_1 * b_2$", `<span class="math inline">\(a_1 * b_2\)</span>`},
+		{"escaped html", "$a < b$", `<span class="math inline">\(a &lt; b\)</span>`},
+		{"display inline", "So $$x^2$$ here", `<span class="math display">\[x^2\]</span>`},
+		{"display block", "$$\n\\sum_{i=1}^n i\n= x\n$$", "<div class=\"math display\">\\[\\sum_{i=1}^n i\n= x\\]</div>"},
+		{"one line block", "$$ x = y $$", `<div class="math display">\[x = y\]</div>`},
+		{"prices", "It costs $5 or $10 today", "<p>It costs $5 or $10 today</p>"},
+		{"space after opening", "a $ b$ c", "<p>a $ b$ c</p>"},
+		{"code span", "`$x$`", "<code>$x$</code>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := renderMarkdown(t, config.MarkdownConfig{Math: true}, tt.markdown)
+			if !strings.Contains(html, tt.want) {
+				t.Errorf("output does not contain %q:\n%s", tt.want, html)
+			}
+		})
+	}
+
+	// Without the setting dollars are text
+	if html := renderMarkdown(t, config.MarkdownConfig{}, "$x_1$"); strings.Contains(html, "math") {
+		t.Errorf("math rendered although disabled:\n%s", html)
+	}
+}
+
+func TestMathRenderer(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":     "title = \"Math\"\n",
+		"posts/euler.md": markdownFile("title: Euler\ndate: 2024-01-02", "$e^{i\\pi} + 1 = 0$"),
+	})
+	buildTestSite(t)
+	assertNotContains(t, "posts/euler.html", "katex")
+
+	// The default theme loads KaTeX on every page once math is on
+	writeSite(t, map[string]string{"bazel.toml": "title = \"Math\"\n[markdown]\nmath = true\n"})
+	buildTestSite(t)
+	for _, file := range []string{"posts/euler.html", "index.html"} {
+		assertContains(t, file, "katex.min.js")
+		assertContains(t, file, "renderMathInElement(document.body")
+	}
+	assertContains(t, "posts/euler.html", `<span class="math inline">\(e^{i\pi} + 1 = 0\)</span>`)
+
+	// A theme overriding the head partial can include the math partial itself
+	writeSite(t, map[string]string{
+		"themes/default/layouts/partials/head.html": "<title>{{.Title}}</title>{{template \"math\" .}}",
+	})
+	buildTestSite(t)
+	assertContains(t, "posts/euler.html", "auto-render.min.js")
+}
+
+func TestMarkdownSettingsInvalidateCache(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":     "title = \"Markdown\"\n",
+		"posts/quote.md": markdownFile("title: Quote\ndate: 2024-01-02", `"Quoted"`),
+	})
+	buildTestSite(t)
+	assertContains(t, "posts/quote.html", "&quot;Quoted&quot;")
+
+	writeSite(t, map[string]string{"bazel.toml": "title = \"Markdown\"\n[markdown]\ntypographer = true\n"})
+	buildTestSite(t)
+	assertContains(t, "posts/quote.html", "&ldquo;Quoted&rdquo;")
+}
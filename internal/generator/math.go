@@ -0,0 +1,224 @@
+package generator
+
+import (
+	"bytes"
+	"html"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Math is written out as TeX between \( \) and \[ \], the default delimiters
+// of both KaTeX's auto-render extension and MathJax, and is otherwise left
+// untouched by markdown so underscores and backslashes survive.
+
+var (
+	kindMathInline = ast.NewNodeKind("MathInline")
+	kindMathBlock  = ast.NewNodeKind("MathBlock")
+)
+
+// mathInline is $tex$, or $$tex$$ within a paragraph
+type mathInline struct {
+	ast.BaseInline
+	TeX     []byte
+	Display bool
+}
+
+// Kind implements ast.Node
+func (n *mathInline) Kind() ast.NodeKind {
+	return kindMathInline
+}
+
+// Dump implements ast.Node
+func (n *mathInline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"TeX": string(n.TeX)}, nil)
+}
+
+// mathBlock is a $$ block spanning one or more lines
+type mathBlock struct {
+	ast.BaseBlock
+	TeX []byte
+
+	closed bool // The closing $$ was on the opening line
+}
+
+// Kind implements ast.Node
+func (n *mathBlock) Kind() ast.NodeKind {
+	return kindMathBlock
+}
+
+// Dump implements ast.Node
+func (n *mathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"TeX": string(n.TeX)}, nil)
+}
+
+// IsRaw implements ast.Node
+func (n *mathBlock) IsRaw() bool {
+	return true
+}
+
+var mathDelim = []byte("$$")
+
+// mathInlineParser parses $tex$ and $$tex$$ on a single line. Like pandoc, an
+// opening $ must not be followed by a space and a closing $ must not follow a
+// space or precede a digit, so prices such as $5 and $10 stay text.
+type mathInlineParser struct{}
+
+// Trigger implements parser.InlineParser
+func (mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Parse implements parser.InlineParser
+func (mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	display := bytes.HasPrefix(line, mathDelim)
+	delim := 1
+	if display {
+		delim = 2
+	}
+
+	body := line[delim:]
+	if len(body) == 0 || util.IsSpace(body[0]) {
+		return nil
+	}
+
+	end := -1
+	for i := 1; i < len(body); i++ {
+		switch {
+		case body[i] == '\\':
+			i++ // Skip escaped characters such as \$
+		case display && bytes.HasPrefix(body[i:], mathDelim):
+			end = i
+		case !display && body[i] == '$' && !util.IsSpace(body[i-1]) &&
+			(i+1 >= len(body) || body[i+1] < '0' || body[i+1] > '9'):
+			end = i
+		}
+		if end > 0 {
+			break
+		}
+	}
+	if end < 0 {
+		return nil
+	}
+
+	block.Advance(delim + end + delim)
+	return &mathInline{TeX: append([]byte(nil), body[:end]...), Display: display}
+}
+
+// mathBlockParser parses display math from a line holding just $$ up to a
+// line ending with $$, or $$tex$$ on a line of its own
+type mathBlockParser struct{}
+
+// Trigger implements parser.BlockParser
+func (mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Open implements parser.BlockParser
+func (mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, _ := reader.PeekLine()
+	trimmed := bytes.TrimSpace(line)
+	if !bytes.HasPrefix(trimmed, mathDelim) {
+		return nil, parser.NoChildren
+	}
+
+	node := &mathBlock{}
+	rest := trimmed[len(mathDelim):]
+	switch {
+	case len(rest) == 0:
+		// The TeX follows on the next lines
+	case len(rest) >= len(mathDelim) && bytes.HasSuffix(rest, mathDelim):
+		// $$ tex $$ on a line of its own
+		node.TeX = rest[:len(rest)-len(mathDelim)]
+		node.closed = true
+	default:
+		return nil, parser.NoChildren // Inline math followed by text
+	}
+	reader.AdvanceToEOL()
+	return node, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser
+func (mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*mathBlock)
+	line, _ := reader.PeekLine()
+	if n.closed || line == nil {
+		return parser.Close
+	}
+	reader.AdvanceToEOL()
+
+	trimmed := bytes.TrimRight(line, " \t\r\n")
+	if bytes.HasSuffix(trimmed, mathDelim) {
+		n.TeX = append(n.TeX, trimmed[:len(trimmed)-len(mathDelim)]...)
+		return parser.Close
+	}
+	n.TeX = append(n.TeX, line...)
+	return parser.Continue | parser.NoChildren
+}
+
+// Close implements parser.BlockParser
+func (mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	n := node.(*mathBlock)
+	n.TeX = bytes.TrimSpace(n.TeX)
+}
+
+// CanInterruptParagraph implements parser.BlockParser
+func (mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser
+func (mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// mathRenderer writes math nodes for client side rendering
+type mathRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMathInline, renderMathInline)
+	reg.Register(kindMathBlock, renderMathBlock)
+}
+
+func renderMathInline(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	node := n.(*mathInline)
+	tex := html.EscapeString(string(node.TeX))
+	if node.Display {
+		w.WriteString(`<span class="math display">\[` + tex + `\]</span>`)
+	} else {
+		w.WriteString(`<span class="math inline">\(` + tex + `\)</span>`)
+	}
+	return ast.WalkContinue, nil
+}
+
+func renderMathBlock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	node := n.(*mathBlock)
+	w.WriteString(`<div class="math display">\[` + html.EscapeString(string(node.TeX)) + `\]</div>` + "\n")
+	return ast.WalkContinue, nil
+}
+
+// mathExtension adds $inline$ and $$display$$ math to goldmark
+type mathExtension struct{}
+
+// Extend implements goldmark.Extender
+func (mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(mathBlockParser{}, 850)),
+		parser.WithInlineParsers(util.Prioritized(mathInlineParser{}, 150)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(mathRenderer{}, 100),
+	))
+}
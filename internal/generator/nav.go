@@ -0,0 +1,254 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/bazel_blog/internal/config"
+)
+
+// sectionIndexName is the optional file giving a section its title, intro
+// text, weight and menu
+const sectionIndexName = "_index.md"
+
+// Section is a directory of pages under pages/, listed on its own index page
+type Section struct {
+	Name    string // Directory name
+	Title   string
+	Content string // HTML of the section's _index.md
+	URL     string // pages/<name>/
+	Weight  int
+	Menu    string
+	Pages   []Page
+
+	file       string // Path of _index.md, which may not exist
+	sourceHash string
+}
+
+// MenuItem is a link in a navigation menu
+type MenuItem struct {
+	Name     string
+	URL      string // Relative to public/, or absolute for external links
+	Weight   int
+	External bool
+
+	file string // Source of a page or section, empty for config entries
+}
+
+// loadSection loads the pages in a subdirectory of pages/. It returns nil
+// for empty sections and for sections whose _index.md is an unrequested draft.
+func (s *Site) loadSection(pagesDir, name string) (*Section, error) {
+	dir := filepath.Join(pagesDir, name)
+	section := &Section{
+		Name:  name,
+		Title: defaultTitle(name),
+		URL:   "pages/" + name + "/",
+		file:  filepath.Join(dir, sectionIndexName),
+
+		sourceHash: hashStrings(name),
+	}
+
+	var errs []error
+	if info, err := os.Stat(section.file); err == nil {
+		index, err := s.loadPage(dir, name, info, false)
+		if err != nil {
+			// Keep checking the section's pages so every problem is reported
+			errs = append(errs, err)
+			index = &Page{Title: section.Title}
+		} else if index == nil {
+			return nil, nil // A draft section
+		}
+		if index.Title != defaultTitle(sectionIndexName) {
+			section.Title = index.Title
+		}
+		section.Content = index.Content
+		section.Weight = index.Weight
+		section.Menu = index.Menu
+		section.sourceHash = index.sourceHash
+	}
+
+	// Pages unpublished from the section wait in drafts/<section>/
+	dirs := []string{dir}
+	if s.Options.Drafts {
+		dirs = append(dirs, filepath.Join(pagesDir, draftsDirName, name))
+	}
+	for i, dir := range dirs {
+		files, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if file.IsDir() || file.Name() == sectionIndexName {
+				continue // Sections are a single level deep
+			}
+			page, err := s.loadPage(dir, name, file, i > 0)
+			if err != nil {
+				errs = append(errs, err)
+			} else if page != nil {
+				section.Pages = append(section.Pages, *page)
+				s.Pages = append(s.Pages, *page)
+			}
+		}
+	}
+	if len(section.Pages) == 0 && section.Content == "" {
+		return nil, errors.Join(errs...)
+	}
+
+	sortPages(section.Pages)
+	return section, errors.Join(errs...)
+}
+
+// defaultTitle is the title of a page or section without one, its file
+// name with underscores as spaces
+func defaultTitle(name string) string {
+	return strings.TrimSuffix(strings.TrimSpace(strings.ReplaceAll(name, "_", " ")), ".md")
+}
+
+// weightLess orders by weight, with unweighted (zero) entries last
+func weightLess(a, b int) bool {
+	if (a == 0) != (b == 0) {
+		return a != 0
+	}
+	return a < b
+}
+
+// sortPages orders pages by weight, keeping the file order of equal weights
+func sortPages(pages []Page) {
+	sort.SliceStable(pages, func(i, j int) bool {
+		return weightLess(pages[i].Weight, pages[j].Weight)
+	})
+}
+
+// buildMenus collects the navigation menus. Top-level pages and sections join
+// the main menu unless their frontmatter names another menu or "none", pages
+// within a section only when they name a menu, and [[menu.<name>]] entries
+// from the config are added to theirs. Each menu is ordered by weight, and
+// unweighted entries keep the order of their files, config entries last.
+func (s *Site) buildMenus() {
+	menus := make(map[string][]MenuItem)
+	add := func(menu string, item MenuItem) {
+		switch menu {
+		case "none":
+			return
+		case "":
+			menu = "main"
+		}
+		menus[menu] = append(menus[menu], item)
+	}
+
+	for _, page := range s.Pages {
+		if page.Section != "" && page.Menu == "" {
+			continue // Listed on the section's index page
+		}
+		add(page.Menu, MenuItem{Name: page.Title, URL: page.URL, Weight: page.Weight, file: page.path})
+	}
+	for _, section := range s.Sections {
+		add(section.Menu, MenuItem{Name: section.Title, URL: section.URL, Weight: section.Weight, file: section.file})
+	}
+
+	for name, entries := range s.Config.Menu {
+		for _, entry := range entries {
+			item := MenuItem{Name: entry.Name, URL: entry.URL, Weight: entry.Weight}
+			if strings.Contains(entry.URL, "://") || strings.HasPrefix(entry.URL, "//") || strings.HasPrefix(entry.URL, "mailto:") {
+				item.External = true
+			} else {
+				item.URL = strings.TrimPrefix(entry.URL, "/")
+			}
+			menus[name] = append(menus[name], item)
+		}
+	}
+
+	for _, items := range menus {
+		sort.SliceStable(items, func(i, j int) bool {
+			a, b := items[i], items[j]
+			switch {
+			case a.Weight != b.Weight:
+				return weightLess(a.Weight, b.Weight)
+			case (a.file == "") != (b.file == ""):
+				return a.file != ""
+			}
+			return a.file < b.file
+		})
+	}
+	s.Menus = menus
+}
+
+func (s *Site) generateSections() error {
+	return parallel(len(s.Sections), func(i int) error {
+		section := s.Sections[i]
+		err := s.renderLayout("section", section.URL+"index.html", LayoutData{
+			Title:       section.Title,
+			Description: section.Title + " - " + s.Config.Description,
+			Content:     template.HTML(section.Content),
+			URL:         section.URL,
+			Permalink:   s.absURL(section.URL),
+			Section:     section,
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", section.file, err)
+		}
+		return nil
+	})
+}
+
+// NavEntry is a markdown page or a section in the main menu
+type NavEntry struct {
+	Name  string // Page name as `bazel page` takes it, or the section directory
+	Title string
+	File  string // Markdown file its weight is stored in
+}
+
+// ListNavEntries returns the pages and sections of the main menu in the
+// order the site shows them. HTML pages and config entries are left out as
+// their position cannot be changed from here.
+func ListNavEntries() ([]NavEntry, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	s, err := newSite(cfg, BuildOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadPages(); err != nil {
+		return nil, fmt.Errorf("failed to load pages: %w", err)
+	}
+
+	var entries []NavEntry
+	for _, item := range s.Menus["main"] {
+		if !strings.HasSuffix(item.file, ".md") {
+			continue
+		}
+		name, _ := filepath.Rel(cfg.PagesPath(), item.file)
+		if filepath.Base(name) == sectionIndexName {
+			name = filepath.Dir(name)
+		}
+		name = strings.TrimSuffix(filepath.ToSlash(name), ".md")
+		entries = append(entries, NavEntry{Name: name, Title: item.Name, File: item.file})
+	}
+	return entries, nil
+}
+
+// SaveNavOrder stores the order of the entries by writing weights of 10, 20,
+// 30 and so on into their frontmatter. A section without an _index.md gets
+// one holding just its weight.
+func SaveNavOrder(entries []NavEntry) error {
+	for i, entry := range entries {
+		if err := setFrontmatterValue(entry.File, "weight", strconv.Itoa((i+1)*10)); err != nil {
+			return fmt.Errorf("failed to save the order of %s: %w", entry.File, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// menuLinks returns the link texts of a nav element in a built page
+func menuLinks(t *testing.T, name, class string) []string {
+	t.Helper()
+	html := readOutput(t, name)
+	start := strings.Index(html, `<nav class="`+class+`"`)
+	if start < 0 {
+		t.Fatalf("%s has no %s", name, class)
+	}
+	nav := html[start:]
+	nav = nav[:strings.Index(nav, "</nav>")]
+
+	var links []string
+	for _, part := range strings.Split(nav, "<a ")[1:] {
+		text := part[strings.Index(part, ">")+1:]
+		links = append(links, text[:strings.Index(text, "</a>")])
+	}
+	return links
+}
+
+var navSite = map[string]string{
+	"bazel.toml": `title = "Nav"
+
+[[menu.main]]
+name = "GitHub"
+url = "https://github.com/example"
+
+[[menu.main]]
+name = "Tags"
+url = "/tags/"
+weight = 15
+
+[[menu.footer]]
+name = "Feed"
+url = "/feed.xml"
+`,
+	"posts/hello.md":        markdownFile("title: Hello\ndate: 2024-01-02\ntags: [go]", "Hello"),
+	"pages/about.md":        markdownFile("title: About\nweight: 20", "About"),
+	"pages/contact.md":      markdownFile("title: Contact", "Contact"),
+	"pages/home.md":         markdownFile("title: Start\nweight: 10", "Start"),
+	"pages/legal.md":        markdownFile("title: Legal\nmenu: footer", "Legal"),
+	"pages/hidden.md":       markdownFile("title: Hidden\nmenu: none", "Hidden"),
+	"pages/docs/_index.md":  markdownFile("title: Documentation\nweight: 30", "All the docs"),
+	"pages/docs/usage.md":   markdownFile("title: Usage\nweight: 2", "Use it"),
+	"pages/docs/install.md": markdownFile("title: Install\nweight: 1", "Install it"),
+	"pages/docs/faq.md":     markdownFile("title: FAQ\nmenu: footer", "Questions"),
+	"pages/guides/intro.md": markdownFile("title: Intro", "No index file"),
+}
+
+func TestMenus(t *testing.T) {
+	newTestSite(t, navSite)
+	buildTestSite(t)
+
+	tests := []struct {
+		class string
+		want  []string
+	}{
+		{"site-nav", []string{"Start", "Tags", "About", "Documentation", "Contact", "guides", "GitHub"}},
+		{"footer-nav", []string{"FAQ", "Legal", "Feed"}},
+	}
+	for _, tt := range tests {
+		got := menuLinks(t, "index.html", tt.class)
+		if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("%s = %q, want %q", tt.class, got, tt.want)
+		}
+	}
+
+	assertContains(t, "index.html",
+		`<a href="https://github.com/example">GitHub</a>`,
+		`<a href="./tags/">Tags</a>`,
+		`<a href="./feed.xml">Feed</a>`)
+	assertContains(t, "pages/about.html", `<a href="../pages/about.html" aria-current="page">About</a>`)
+	if !hasOutput("pages/hidden.html") {
+		t.Error("a page without a menu was not built")
+	}
+}
+
+func TestSections(t *testing.T) {
+	newTestSite(t, navSite)
+	buildTestSite(t)
+
+	assertContains(t, "pages/docs/index.html", "All the docs")
+	html := readOutput(t, "pages/docs/index.html")
+	install, usage, faq := strings.Index(html, ">Install<"), strings.Index(html, ">Usage<"), strings.Index(html, ">FAQ<")
+	if install < 0 || install > usage || usage > faq {
+		t.Errorf("section pages are not ordered by weight:\n%s", html)
+	}
+	assertContains(t, "pages/docs/install.html", "Install it")
+	assertContains(t, "pages/guides/index.html", "<h1>guides</h1>", ">Intro<")
+}
+
+func TestSaveNavOrder(t *testing.T) {
+	files := map[string]string{}
+	for name, content := range navSite {
+		files[name] = content
+	}
+	delete(files, "pages/docs/_index.md")
+	files["pages/about.md"] = "+++\ntitle = \"About\"\nweight = 20\n+++\n\nAbout\n"
+	files["pages/contact.md"] = "{\n  \"title\": \"Contact\"\n}\n\nContact\n"
+	newTestSite(t, files)
+
+	entries, err := ListNavEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, entry := range entries {
+		titles = append(titles, entry.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "Start, About, Contact, docs, guides" {
+		t.Fatalf("ListNavEntries() = %s", got)
+	}
+
+	// Move Contact first and the docs section second
+	entries[0], entries[1], entries[2], entries[3] = entries[2], entries[3], entries[0], entries[1]
+	if err := SaveNavOrder(entries); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, want := range map[string]string{
+		"pages/contact.md":       "{\n  \"weight\": 10,\n  \"title\": \"Contact\"\n}\n",
+		"pages/docs/_index.md":   "---\nweight: 20\n---\n",
+		"pages/home.md":          "---\ntitle: Start\nweight: 30\n---\n",
+		"pages/about.md":         "+++\ntitle = \"About\"\nweight = 40\n+++\n",
+		"pages/guides/_index.md": "---\nweight: 50\n---\n",
+	} {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(content), want) {
+			t.Errorf("%s =\n%s\nwant it to start with\n%s", file, content, want)
+		}
+	}
+
+	buildTestSite(t)
+	if got := menuLinks(t, "index.html", "site-nav"); strings.Join(got, ", ") != "Contact, Tags, docs, Start, About, guides, GitHub" {
+		t.Errorf("site-nav after reordering = %q", got)
+	}
+}
+
+func TestWeightLess(t *testing.T) {
+	tests := []struct {
+		a, b int
+		want bool
+	}{
+		{1, 2, true},
+		{2, 1, false},
+		{1, 0, true},
+		{0, 1, false},
+		{0, 0, false},
+		{-5, 1, true},
+	}
+	for _, tt := range tests {
+		if got := weightLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("weightLess(%d, %d) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
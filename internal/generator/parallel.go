@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// workers is the size of the pool parallel runs calls on, one per CPU
+var workers = runtime.NumCPU()
+
+// parallel calls fn for every index below n on a pool of workers goroutines.
+// It waits for all calls and returns their errors joined in index order, so
+// a build reports every failing file at once and in a stable order.
+func parallel(n int, fn func(i int) error) error {
+	pool := max(1, min(workers, n))
+
+	errs := make([]error, n)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < pool; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
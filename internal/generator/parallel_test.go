@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withWorkers sets the size of the worker pool for one test
+func withWorkers(t *testing.T, n int) {
+	t.Helper()
+	old := workers
+	workers = n
+	t.Cleanup(func() { workers = old })
+}
+
+func TestParallelJoinsErrorsInOrder(t *testing.T) {
+	withWorkers(t, 4)
+
+	done := make(chan error)
+	go func() {
+		done <- parallel(20, func(i int) error {
+			if i%5 == 0 {
+				return fmt.Errorf("job %d failed", i)
+			}
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-done:
+		want := "job 0 failed\njob 5 failed\njob 10 failed\njob 15 failed"
+		if err == nil || err.Error() != want {
+			t.Errorf("error = %v, want %q", err, want)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("parallel did not return after jobs failed")
+	}
+}
+
+func TestParallelNoJobs(t *testing.T) {
+	if err := parallel(0, func(int) error { return errors.New("called") }); err != nil {
+		t.Errorf("parallel(0) = %v", err)
+	}
+}
+
+// parallelTestSite is a site with enough posts and pages to keep every
+// worker busy, sharing tags and categories across posts
+func parallelTestSite() map[string]string {
+	files := map[string]string{
+		"bazel.toml":     "title = \"Parallel\"\nbase_url = \"https://example.com\"\nposts_per_page = 5\n",
+		"pages/about.md": markdownFile("title: About", "About this site"),
+		"pages/help.md":  markdownFile("title: Help", "## Steps\n\nRun it"),
+	}
+	for i := 1; i <= 40; i++ {
+		frontmatter := fmt.Sprintf("title: Post %d\ndate: 2024-%02d-%02d\ntags: [tag%d, common]\ncategories: [cat%d]", i, i%12+1, i%28+1, i%3, i%2)
+		body := fmt.Sprintf("## Intro\n\nPost number %d with some `code`.\n\n## Details\n\nMore text.", i)
+		files[fmt.Sprintf("posts/post_%02d.md", i)] = markdownFile(frontmatter, body)
+	}
+	return files
+}
+
+// readTree returns the files below dir by slash separated relative path
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	tree := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		tree[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestParallelBuildMatchesSequential(t *testing.T) {
+	newTestSite(t, parallelTestSite())
+
+	build := func(n int) map[string]string {
+		withWorkers(t, n)
+		if err := BuildSiteWithOptions(BuildOptions{Clean: true}); err != nil {
+			t.Fatalf("build with %d workers failed: %v", n, err)
+		}
+		return readTree(t, "public")
+	}
+	sequential := build(1)
+	concurrent := build(8)
+
+	if len(sequential) != len(concurrent) {
+		t.Errorf("%d files with 1 worker, %d with 8", len(sequential), len(concurrent))
+	}
+	for name, want := range sequential {
+		if got, ok := concurrent[name]; !ok {
+			t.Errorf("%s is missing with 8 workers", name)
+		} else if got != want {
+			t.Errorf("%s differs between 1 and 8 workers", name)
+		}
+	}
+}
+
+func TestParallelBuildReportsRenderErrors(t *testing.T) {
+	withWorkers(t, 4)
+	files := parallelTestSite()
+	files["themes/default/layouts/post.html"] = `{{define "main"}}{{index .Post.Tags 5}}{{end}}`
+	newTestSite(t, files)
+
+	done := make(chan error)
+	go func() {
+		done <- BuildSiteWithOptions(BuildOptions{})
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("build succeeded with a failing post layout")
+		}
+		for _, post := range []string{"post_01.md", "post_20.md", "post_40.md"} {
+			if !strings.Contains(err.Error(), post) {
+				t.Errorf("error does not mention %s: %v", post, err)
+			}
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("build did not return after renders failed")
+	}
+}
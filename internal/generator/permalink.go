@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultPermalink keeps the original posts/<filename>.html layout
+const defaultPermalink = "/posts/:filename.html"
+
+// expandPermalink fills in a permalink pattern such as "/:year/:month/:slug/"
+// for post. The result is relative to public/; a trailing slash means the post
+// is written to index.html inside that directory.
+func expandPermalink(pattern string, post *Post) string {
+	if pattern == "" {
+		pattern = defaultPermalink
+	}
+
+	replacer := strings.NewReplacer(
+		":year", fmt.Sprintf("%04d", post.Date.Year()),
+		":month", fmt.Sprintf("%02d", int(post.Date.Month())),
+		":day", fmt.Sprintf("%02d", post.Date.Day()),
+		":slug", post.Slug,
+		":title", slugify(post.Title),
+		":filename", strings.TrimSuffix(strings.TrimSuffix(post.Filename, "/"+bundleIndex), ".md"),
+	)
+
+	return strings.TrimPrefix(replacer.Replace(pattern), "/")
+}
+
+// outputFile returns the file a URL is written to, relative to public/
+func outputFile(url string) string {
+	if url == "" || strings.HasSuffix(url, "/") {
+		return url + "index.html"
+	}
+	return url
+}
+
+// assignPermalinks sets the URL of every post from the permalink pattern and
+// reports posts that would overwrite each other.
+func (s *Site) assignPermalinks() error {
+	seen := make(map[string]string, len(s.Posts))
+	for i := range s.Posts {
+		post := &s.Posts[i]
+		post.URL = expandPermalink(s.Config.Permalink, post)
+		post.Permalink = s.absURL(post.URL)
+
+		file := outputFile(post.URL)
+		if other, ok := seen[file]; ok {
+			return fmt.Errorf("posts %s and %s both have the URL /%s; give one of them a different slug", other, post.Filename, post.URL)
+		}
+		seen[file] = post.Filename
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandPermalink(t *testing.T) {
+	post := &Post{
+		Title:    "Hello, World!",
+		Date:     time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC),
+		Filename: "hello_world.md",
+		Slug:     "hello",
+	}
+
+	tests := map[string]string{
+		"":                         "posts/hello_world.html",
+		"/posts/:filename.html":    "posts/hello_world.html",
+		"/:year/:month/:slug/":     "2024/03/hello/",
+		"/:year/:month/:day/:slug": "2024/03/05/hello",
+		"/blog/:title/":            "blog/hello-world/",
+		"/:slug.html":              "hello.html",
+	}
+	for pattern, want := range tests {
+		if got := expandPermalink(pattern, post); got != want {
+			t.Errorf("expandPermalink(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestOutputFile(t *testing.T) {
+	tests := map[string]string{
+		"":                 "index.html",
+		"2024/03/hello/":   "2024/03/hello/index.html",
+		"posts/hello.html": "posts/hello.html",
+	}
+	for url, want := range tests {
+		if got := outputFile(url); got != want {
+			t.Errorf("outputFile(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestPermalinkBuild(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":       "base_url = \"https://example.com/\"\npermalink = \"/:year/:month/:slug/\"\n",
+		"posts/My_Post.md": markdownFile("title: My Post\ndate: 2024-03-05", "body"),
+		"posts/custom.md":  markdownFile("title: Custom\ndate: 2024-04-01\nslug: chosen-slug", "body"),
+		"pages/about.md":   markdownFile("title: About", "[home](../index.html)"),
+	})
+	buildTestSite(t)
+
+	for _, file := range []string{"2024/03/my-post/index.html", "2024/04/chosen-slug/index.html"} {
+		if !hasOutput(file) {
+			t.Errorf("%s was not built", file)
+		}
+	}
+
+	// Nested posts link back to the root and are linked from it
+	assertContains(t, "2024/03/my-post/index.html", `href="../../../style.css"`, `<link rel="canonical" href="https://example.com/2024/03/my-post/">`)
+	assertContains(t, "index.html", `href="./2024/03/my-post/"`, `href="./2024/04/chosen-slug/"`)
+}
+
+func TestPermalinkConflict(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":   "permalink = \"/:slug/\"\n",
+		"posts/one.md": markdownFile("title: One\ndate: 2024-03-05\nslug: same", "body"),
+		"posts/two.md": markdownFile("title: Two\ndate: 2024-03-06\nslug: same", "body"),
+	})
+
+	err := BuildSite()
+	if err == nil || !strings.Contains(err.Error(), "both have the URL /same/") {
+		t.Errorf("error = %v, want a URL conflict", err)
+	}
+}
+
+func TestResolvePost(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":           "title = \"Resolve\"\nbase_url = \"https://example.com/blog\"\npermalink = \"/:year/:slug/\"\n",
+		"posts/hello.md":       markdownFile("title: Hello\ndate: 2024-01-02\nslug: hello-world", "Hello"),
+		"posts/trip/index.md":  markdownFile("title: Trip\ndate: 2024-01-03", "Trip"),
+		"posts/drafts/idea.md": markdownFile("title: Idea\ndate: 2999-01-02", "Idea"),
+		"posts/first.md":       markdownFile("title: First\ndate: 2023-01-02\nslug: same", "First"),
+		"posts/second.md":      markdownFile("title: Second\ndate: 2024-01-02\nslug: same", "Second"),
+	})
+
+	tests := []struct {
+		ref, want, wantErr string
+	}{
+		{ref: "hello", want: "hello"},
+		{ref: "hello-world", want: "hello"},
+		{ref: "2024/hello-world", want: "hello"},
+		{ref: "/2024/trip/", want: "trip"},
+		{ref: "https://example.com/blog/2024/trip/", want: "trip"},
+		{ref: "https://example.com/blog/2999/idea", want: "idea"},
+		{ref: "same", wantErr: "same matches several posts: second, first"},
+		{ref: "missing", wantErr: "post not found: missing"},
+	}
+	for _, tt := range tests {
+		got, err := ResolvePost(tt.ref)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ResolvePost(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ResolvePost(%q) = %q, %v, want %q", tt.ref, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// redirectTemplate is written at every alias of a post. The refresh target is
+// relative so redirects also work on the dev server.
+var redirectTemplate = template.Must(template.New("redirect").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+    <link rel="canonical" href="{{.Permalink}}">
+    <meta name="robots" content="noindex">
+    <meta http-equiv="refresh" content="0; url={{.Target}}">
+</head>
+<body>
+    <p>This page has moved to <a href="{{.Target}}">{{.Title}}</a>.</p>
+</body>
+</html>
+`))
+
+// redirect maps an old path to the post that now lives elsewhere
+type redirect struct {
+	From string // Alias path relative to public/
+	File string // Redirect page written for the alias
+	Post *Post
+}
+
+// collectRedirects gathers the aliases of every post, rejecting aliases that
+// would overwrite a generated page or each other. It runs once every other
+// page, feed and asset is written, so those outputs are all reserved.
+func (s *Site) collectRedirects() ([]redirect, error) {
+	taken := make(map[string]string)
+	if name, _, err := s.redirectsFormat(); err != nil {
+		return nil, err
+	} else if name != "" {
+		taken[name] = "the redirects file"
+	}
+	for _, post := range s.Posts {
+		taken[outputFile(post.URL)] = post.Filename
+	}
+	for _, page := range s.Pages {
+		taken[outputFile(page.URL)] = page.Filename
+	}
+	for _, section := range s.Sections {
+		taken[outputFile(section.URL)] = section.file
+	}
+
+	var redirects []redirect
+	for i := range s.Posts {
+		post := &s.Posts[i]
+		for _, alias := range post.Aliases {
+			from := strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+alias)), "/")
+			if strings.HasSuffix(alias, "/") && from != "" {
+				from += "/"
+			}
+			if from == "" {
+				return nil, fmt.Errorf("%s: alias %q points at the site root", post.Filename, alias)
+			}
+
+			// An alias without an extension, such as /old-post, is written
+			// as old-post/index.html so static hosts serve it as HTML. A
+			// file named old-post would block that directory too.
+			file := outputFile(from)
+			paths := []string{file}
+			if !strings.HasSuffix(from, "/") && path.Ext(from) == "" {
+				file = from + "/index.html"
+				paths = append(paths, file)
+			}
+			for _, out := range paths {
+				if owner, ok := taken[out]; ok {
+					return nil, fmt.Errorf("%s: alias %q conflicts with %s", post.Filename, alias, owner)
+				}
+				if s.cache.hasOutput(out) {
+					return nil, fmt.Errorf("%s: alias %q conflicts with generated %s", post.Filename, alias, out)
+				}
+			}
+			taken[file] = post.Filename
+
+			redirects = append(redirects, redirect{From: from, File: file, Post: post})
+		}
+	}
+
+	sort.Slice(redirects, func(i, j int) bool {
+		return redirects[i].From < redirects[j].From
+	})
+	return redirects, nil
+}
+
+// generateRedirects writes a meta refresh page at every post alias and, when
+// configured, a server redirect file covering the same paths.
+func (s *Site) generateRedirects() error {
+	redirects, err := s.collectRedirects()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range redirects {
+		var buf bytes.Buffer
+		err := redirectTemplate.Execute(&buf, struct {
+			Title     string
+			Permalink string
+			Target    string
+		}{
+			Title:     r.Post.Title,
+			Permalink: r.Post.Permalink,
+			Target:    relRoot(r.File) + r.Post.URL,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.writeOutput(r.File, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return s.writeRedirectsFile(redirects)
+}
+
+// redirectsFormat returns the server redirect file selected by the redirects
+// option and its line format: "netlify" for a _redirects file or "nginx" for
+// a map. The name is empty when no file is written.
+func (s *Site) redirectsFormat() (name, line string, err error) {
+	switch s.Config.Redirects {
+	case "":
+		return "", "", nil
+	case "netlify":
+		return "_redirects", "/%s /%s 301\n", nil
+	case "nginx":
+		return "redirects.map", "/%s /%s;\n", nil
+	}
+	return "", "", fmt.Errorf("unknown redirects format %q (use \"netlify\" or \"nginx\")", s.Config.Redirects)
+}
+
+// writeRedirectsFile writes the aliases in the format selected by the
+// redirects option
+func (s *Site) writeRedirectsFile(redirects []redirect) error {
+	name, line, err := s.redirectsFormat()
+	if err != nil || name == "" {
+		return err
+	}
+
+	var b strings.Builder
+	for _, r := range redirects {
+		fmt.Fprintf(&b, line, r.From, r.Post.URL)
+	}
+
+	return s.writeOutput(name, []byte(b.String()))
+}
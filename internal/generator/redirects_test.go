@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedirects(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":     "base_url = \"https://example.com\"\nredirects = \"netlify\"\n",
+		"posts/moved.md": markdownFile("title: Moved\ndate: 2024-01-02\naliases: [/old/path/, legacy/moved.html, /old-post]", "body"),
+	})
+	buildTestSite(t)
+
+	// The refresh target is relative to the alias, the canonical URL absolute
+	assertContains(t, "old/path/index.html",
+		`content="0; url=../../posts/moved.html"`,
+		`<link rel="canonical" href="https://example.com/posts/moved.html">`,
+		`<meta name="robots" content="noindex">`)
+	assertContains(t, "legacy/moved.html", `url=../posts/moved.html"`)
+
+	// Extensionless aliases become directories so they are served as HTML
+	assertContains(t, "old-post/index.html", `url=../posts/moved.html"`)
+	if info, err := os.Stat(filepath.Join("public", "old-post")); err != nil || !info.IsDir() {
+		t.Errorf("old-post is not a directory: %v", err)
+	}
+
+	assertContains(t, "_redirects",
+		"/legacy/moved.html /posts/moved.html 301\n",
+		"/old-post /posts/moved.html 301\n",
+		"/old/path/ /posts/moved.html 301\n")
+}
+
+func TestRedirectsNginx(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":     "redirects = \"nginx\"\n",
+		"posts/moved.md": markdownFile("title: Moved\ndate: 2024-01-02\naliases: old.html", "body"),
+	})
+	buildTestSite(t)
+
+	assertContains(t, "redirects.map", "/old.html /posts/moved.html;\n")
+	if hasOutput("_redirects") {
+		t.Error("_redirects was written for the nginx format")
+	}
+}
+
+func TestRedirectErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "alias of another post",
+			files: map[string]string{
+				"posts/a.md": markdownFile("title: A\ndate: 2024-01-02\naliases: posts/b.html", "body"),
+				"posts/b.md": markdownFile("title: B\ndate: 2024-01-03", "body"),
+			},
+			wantErr: `alias "posts/b.html" conflicts with b.md`,
+		},
+		{
+			name: "alias of a page",
+			files: map[string]string{
+				"posts/a.md":     markdownFile("title: A\ndate: 2024-01-02\naliases: /pages/about.html", "body"),
+				"pages/about.md": markdownFile("title: About", "body"),
+			},
+			wantErr: "conflicts with about.md",
+		},
+		{
+			name: "alias of a feed",
+			files: map[string]string{
+				"posts/a.md": markdownFile("title: A\ndate: 2024-01-02\naliases: /feed.xml", "body"),
+			},
+			wantErr: `alias "/feed.xml" conflicts with generated feed.xml`,
+		},
+		{
+			name: "alias of a tag page",
+			files: map[string]string{
+				"posts/a.md": markdownFile("title: A\ndate: 2024-01-02\ntags: [go]\naliases: /tags/go/", "body"),
+			},
+			wantErr: "conflicts with generated",
+		},
+		{
+			name: "alias of a bundle asset",
+			files: map[string]string{
+				"posts/a.md":           markdownFile("title: A\ndate: 2024-01-02\naliases: /posts/trip/photo.jpg", "body"),
+				"posts/trip/index.md":  markdownFile("title: Trip\ndate: 2024-01-03", "body"),
+				"posts/trip/photo.jpg": "jpeg",
+			},
+			wantErr: "conflicts with generated",
+		},
+		{
+			name: "alias of the redirects file",
+			files: map[string]string{
+				"bazel.toml": "redirects = \"netlify\"\n",
+				"posts/a.md": markdownFile("title: A\ndate: 2024-01-02\naliases: /_redirects", "body"),
+			},
+			wantErr: "conflicts with the redirects file",
+		},
+		{
+			name: "same alias twice",
+			files: map[string]string{
+				"posts/a.md": markdownFile("title: A\ndate: 2024-01-02\naliases: old/", "body"),
+				"posts/b.md": markdownFile("title: B\ndate: 2024-01-03\naliases: /old/", "body"),
+			},
+			wantErr: "conflicts with",
+		},
+		{
+			name: "site root",
+			files: map[string]string{
+				"posts/a.md": markdownFile("title: A\ndate: 2024-01-02\naliases: /", "body"),
+			},
+			wantErr: "points at the site root",
+		},
+		{
+			name: "unknown format",
+			files: map[string]string{
+				"bazel.toml": "redirects = \"apache\"\n",
+				"posts/a.md": markdownFile("title: A\ndate: 2024-01-02\naliases: old/", "body"),
+			},
+			wantErr: `unknown redirects format "apache"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestSite(t, tt.files)
+			err := BuildSite()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
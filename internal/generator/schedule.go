@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/bazel_blog/internal/config"
+)
+
+// postSchedule is when a post goes live and, optionally, when it is taken down
+type postSchedule struct {
+	PublishAt time.Time
+	ExpiresAt time.Time // Zero when the post does not expire
+}
+
+// published reports whether a post with the given schedule is part of the
+// build. Expired posts never are, future ones only when requested.
+func (s *Site) published(sched postSchedule) bool {
+	if !sched.ExpiresAt.IsZero() && !sched.ExpiresAt.After(s.now) {
+		return false
+	}
+	return s.Options.Future || !sched.PublishAt.After(s.now)
+}
+
+// postSchedule works out when a post goes live: its publish_date, or else its
+// date, which falls back to the file's modification time. Dates without a
+// time zone are read in the site's timezone, like the date a post shows, so a
+// cron build releases posts at the time written down. Unparseable dates are
+// ignored outside strict mode.
+func (s *Site) postSchedule(src postSource, content []byte, matter PostMatter) (postSchedule, error) {
+	sched := postSchedule{PublishAt: src.info.ModTime()}
+	if matter.Date != "" {
+		if date, err := parsePostDate(string(matter.Date), matter.Time, s.loc); err == nil {
+			sched.PublishAt = date
+		}
+	}
+
+	fields := []struct {
+		key   string
+		value DateString
+		dst   *time.Time
+	}{
+		{"publish_date", matter.PublishDate, &sched.PublishAt},
+		{"expiry_date", matter.ExpiryDate, &sched.ExpiresAt},
+	}
+	var errs []error
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		parsed, err := parsePostDate(string(field.value), "", s.loc)
+		if err != nil {
+			if s.Options.Strict {
+				errs = append(errs, strictError(src.path, content, field.key, "cannot parse %s %q", field.key, field.value))
+			}
+			continue
+		}
+		*field.dst = parsed
+	}
+	return sched, errors.Join(errs...)
+}
+
+// ScheduledPost is a post that is not live yet or that expires later
+type ScheduledPost struct {
+	Title     string
+	Filename  string    // Relative to the posts directory
+	PublishAt time.Time // In the site's timezone
+	ExpiresAt time.Time // Zero when the post does not expire
+}
+
+// ListScheduled returns the posts of the site in the current directory that
+// go live or expire in the future, ordered by their next change. Drafts are
+// left out since a build would not publish them either.
+func ListScheduled() ([]ScheduledPost, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	loc, err := cfg.Location()
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	s := &Site{Config: cfg, now: time.Now(), loc: loc}
+	sources, err := s.postSources()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list posts: %w", err)
+	}
+
+	var scheduled []ScheduledPost
+	for _, src := range sources {
+		content, err := ioutil.ReadFile(src.path)
+		if err != nil {
+			continue
+		}
+
+		var matter PostMatter
+		if _, err := s.parseFrontmatter(src.path, content, &matter); err != nil {
+			continue
+		}
+		if matter.Draft {
+			continue
+		}
+
+		sched, _ := s.postSchedule(src, content, matter)
+		if !sched.PublishAt.After(s.now) && !sched.ExpiresAt.After(s.now) {
+			continue // Live for good, or already expired
+		}
+
+		title := matter.Title
+		if title == "" {
+			title = strings.TrimSpace(strings.ReplaceAll(src.name, "_", " "))
+		}
+		scheduled = append(scheduled, ScheduledPost{
+			Title:     title,
+			Filename:  src.filename,
+			PublishAt: sched.PublishAt.In(s.loc),
+			ExpiresAt: sched.ExpiresAt.In(s.loc),
+		})
+	}
+
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return scheduled[i].nextChange(s.now).Before(scheduled[j].nextChange(s.now))
+	})
+	return scheduled, nil
+}
+
+// nextChange returns when the post next goes live or expires after now
+func (p ScheduledPost) nextChange(now time.Time) time.Time {
+	if p.PublishAt.After(now) {
+		return p.PublishAt
+	}
+	return p.ExpiresAt
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var scheduleSite = map[string]string{
+	"posts/live.md":      markdownFile("title: Live\ndate: 2024-01-02", "Live"),
+	"posts/future.md":    markdownFile("title: Future\ndate: 2999-01-02", "Future"),
+	"posts/later.md":     markdownFile("title: Later\ndate: 2024-01-03\npublish_date: 2998-05-06", "Later"),
+	"posts/expired.md":   markdownFile("title: Expired\ndate: 2024-01-04\nexpiry_date: 2024-02-01", "Expired"),
+	"posts/expiring.md":  markdownFile("title: Expiring\ndate: 2024-01-05\nexpiry_date: 2997-01-01", "Expiring"),
+	"posts/draft.md":     markdownFile("title: Draft\ndate: 2999-01-01\ndraft: true", "Draft"),
+	"posts/bad_dates.md": markdownFile("title: Bad\ndate: 2024-01-06\npublish_date: whenever", "Bad"),
+}
+
+func TestScheduledBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    BuildOptions
+		built   []string
+		skipped []string
+	}{
+		{
+			name:    "default",
+			built:   []string{"live", "expiring", "bad_dates"},
+			skipped: []string{"future", "later", "expired", "draft"},
+		},
+		{
+			name:    "future",
+			opts:    BuildOptions{Future: true},
+			built:   []string{"live", "future", "later", "expiring"},
+			skipped: []string{"expired", "draft"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestSite(t, scheduleSite)
+			buildTestSiteWith(t, tt.opts)
+
+			for _, name := range tt.built {
+				if !hasOutput("posts/" + name + ".html") {
+					t.Errorf("%s was not built", name)
+				}
+			}
+			for _, name := range tt.skipped {
+				if hasOutput("posts/" + name + ".html") {
+					t.Errorf("%s was built", name)
+				}
+				assertNotContains(t, "index.html", "posts/"+name+".html")
+			}
+		})
+	}
+}
+
+func TestStrictScheduleDates(t *testing.T) {
+	newTestSite(t, scheduleSite)
+	err := BuildSiteWithOptions(BuildOptions{Strict: true})
+	want := `posts/bad_dates.md:4: cannot parse publish_date "whenever"`
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("strict build error = %v, want %q", err, want)
+	}
+}
+
+func TestListScheduled(t *testing.T) {
+	newTestSite(t, scheduleSite)
+
+	scheduled, err := ListScheduled()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, post := range scheduled {
+		got = append(got, post.Filename)
+	}
+	want := []string{"expiring.md", "later.md", "future.md"}
+	if len(got) != len(want) {
+		t.Fatalf("ListScheduled() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ListScheduled() = %q, want %q ordered by next change", got, want)
+			break
+		}
+	}
+	if scheduled[0].Title != "Expiring" || scheduled[0].ExpiresAt.Year() != 2997 {
+		t.Errorf("expiring post = %+v", scheduled[0])
+	}
+}
+
+func TestScheduleTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	newTestSite(t, map[string]string{
+		"bazel.toml":      "title = \"Zones\"\ntimezone = \"Asia/Tokyo\"\n",
+		"posts/future.md": markdownFile("title: Future\ndate: 2999-01-02\ntime: \"10:00\"\nexpiry_date: 2999-02-03 08:30", "Future"),
+		"posts/zoned.md":  markdownFile("title: Zoned\ndate: 2998-01-02T10:00:00Z", "Zoned"),
+	})
+
+	scheduled, err := ListScheduled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]time.Time{
+		"future.md": time.Date(2999, 1, 2, 10, 0, 0, 0, tokyo),
+		"zoned.md":  time.Date(2998, 1, 2, 10, 0, 0, 0, time.UTC),
+	}
+	for _, post := range scheduled {
+		if !post.PublishAt.Equal(want[post.Filename]) {
+			t.Errorf("%s goes live at %s, want %s", post.Filename, post.PublishAt, want[post.Filename])
+		}
+		if post.PublishAt.Location().String() != "Asia/Tokyo" {
+			t.Errorf("%s goes live in %s, want the site's timezone", post.Filename, post.PublishAt.Location())
+		}
+	}
+	if len(scheduled) != 2 || !scheduled[1].ExpiresAt.Equal(time.Date(2999, 2, 3, 8, 30, 0, 0, tokyo)) {
+		t.Errorf("ListScheduled() = %+v", scheduled)
+	}
+
+	writeSite(t, map[string]string{"bazel.toml": "title = \"Zones\"\ntimezone = \"Mars/Olympus\"\n"})
+	if err := BuildSite(); err == nil || !strings.Contains(err.Error(), "timezone") {
+		t.Errorf("build with an unknown timezone error = %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package generator
+
+import "sort"
+
+// SeriesNav places a post within its series for the "Part N of M" box
+type SeriesNav struct {
+	Term *Term // The series, with its parts in order
+	Part int   // 1-based position of the post in Term.Posts
+}
+
+// Total returns the number of parts in the series
+func (n *SeriesNav) Total() int {
+	return len(n.Term.Posts)
+}
+
+// Prev returns the previous part, or nil for the first one
+func (n *SeriesNav) Prev() *Post {
+	if n.Part <= 1 {
+		return nil
+	}
+	return &n.Term.Posts[n.Part-2]
+}
+
+// Next returns the next part, or nil for the last one
+func (n *SeriesNav) Next() *Post {
+	if n.Part >= len(n.Term.Posts) {
+		return nil
+	}
+	return &n.Term.Posts[n.Part]
+}
+
+// seriesTerms returns the series of a post as a taxonomy term list
+func seriesTerms(p *Post) []string {
+	if p.Series == "" {
+		return nil
+	}
+	return []string{p.Series}
+}
+
+// buildSeries orders the parts of every series, which buildTaxonomies grouped
+// like tags, and links each post to its series. Parts are ordered by
+// series_part, and parts without one follow in date order.
+func (s *Site) buildSeries() {
+	series := s.Taxonomies["series"]
+	if series == nil {
+		return
+	}
+
+	byURL := make(map[string]*Post, len(s.Posts))
+	for i := range s.Posts {
+		byURL[s.Posts[i].URL] = &s.Posts[i]
+	}
+
+	for _, term := range series.Terms {
+		posts := term.Posts
+		sort.SliceStable(posts, func(i, j int) bool {
+			a, b := posts[i], posts[j]
+			switch {
+			case a.SeriesPart > 0 && b.SeriesPart > 0:
+				return a.SeriesPart < b.SeriesPart
+			case a.SeriesPart > 0 || b.SeriesPart > 0:
+				return a.SeriesPart > 0
+			}
+			return a.Date.Before(b.Date)
+		})
+
+		for i := range posts {
+			if post := byURL[posts[i].URL]; post != nil {
+				post.SeriesNav = &SeriesNav{Term: term, Part: i + 1}
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSeries(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/intro.md":  markdownFile("title: Intro\ndate: 2024-03-01\nseries: Go Basics\nseries_part: 1", "Intro"),
+		"posts/types.md":  markdownFile("title: Types\ndate: 2024-01-01\nseries: Go Basics\nseries_part: 2", "Types"),
+		"posts/extra.md":  markdownFile("title: Extra\ndate: 2024-02-01\nseries: Go Basics", "Extra"),
+		"posts/bonus.md":  markdownFile("title: Bonus\ndate: 2024-02-15\nseries: Go Basics", "Bonus"),
+		"posts/single.md": markdownFile("title: Single\ndate: 2024-01-05", "Alone"),
+	})
+	buildTestSite(t)
+
+	// Numbered parts come first, then the others by date
+	assertContains(t, "posts/types.html",
+		`Part 2 of 4 in <a href="../series/go-basics/">Go Basics</a>`,
+		`<a class="prev" href="../posts/intro.html">← Intro</a>`,
+		`<a class="next" href="../posts/extra.html">Extra →</a>`)
+	parts := []string{
+		`<li><a href="../posts/intro.html">Intro</a></li>`,
+		`<li><strong>Types</strong></li>`,
+		`<li><a href="../posts/extra.html">Extra</a></li>`,
+		`<li><a href="../posts/bonus.html">Bonus</a></li>`,
+	}
+	html := readOutput(t, "posts/types.html")
+	for i := 1; i < len(parts); i++ {
+		if strings.Index(html, parts[i-1]) > strings.Index(html, parts[i]) {
+			t.Errorf("%s is listed after %s", parts[i-1], parts[i])
+		}
+	}
+	assertContains(t, "posts/types.html", parts...)
+
+	assertContains(t, "posts/intro.html", "Part 1 of 4")
+	assertNotContains(t, "posts/intro.html", `class="prev"`)
+	assertContains(t, "posts/bonus.html", "Part 4 of 4", `class="prev"`)
+	assertNotContains(t, "posts/bonus.html", `class="next"`)
+	assertNotContains(t, "posts/single.html", `class="series"`, `class="series-nav"`)
+
+	assertContains(t, "series/go-basics/index.html", "posts/intro.html", "posts/bonus.html")
+	assertNotContains(t, "series/go-basics/index.html", "posts/single.html")
+}
@@ -0,0 +1,302 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// builtinShortcodes are available to every post and page. A theme can
+// override any of them, or add new ones, in themes/<name>/shortcodes/.
+var builtinShortcodes = map[string]string{
+	"youtube": youtubeShortcode,
+	"gist":    gistShortcode,
+	"figure":  figureShortcode,
+	"note":    noteShortcode,
+}
+
+// ShortcodeData is the value shortcode templates are executed with
+type ShortcodeData struct {
+	Name   string
+	Args   []string          // Positional arguments
+	Params map[string]string // Named arguments, key="value"
+	Inner  template.HTML     // Rendered markdown between the opening and closing tag
+
+	links *bundleLinks
+}
+
+// Get returns a positional argument for an int key and a named one for a
+// string key, or "" when it is not set
+func (d ShortcodeData) Get(key interface{}) string {
+	switch k := key.(type) {
+	case int:
+		if k >= 0 && k < len(d.Args) {
+			return d.Args[k]
+		}
+	case string:
+		return d.Params[k]
+	}
+	return ""
+}
+
+// Link resolves a path relative to the post the way markdown links are, so
+// shortcodes can refer to the files of a page bundle
+func (d ShortcodeData) Link(dest string) string {
+	return string(d.links.rebase([]byte(dest)))
+}
+
+// shortcodeSet holds the parsed shortcode templates of a site
+type shortcodeSet struct {
+	templates *template.Template
+	paired    map[string]bool // Shortcodes that use .Inner and need a closing tag
+	hash      string          // Hash of every template source
+}
+
+// themeDir returns the directory of the active theme
+func (s *Site) themeDir() string {
+	name := s.Config.Theme.Name
+	if name == "" {
+		name = "default"
+	}
+	return filepath.Join("themes", name)
+}
+
+// loadShortcodes parses the built-in shortcodes and those of the active theme
+func (s *Site) loadShortcodes() error {
+	sources := make(map[string]string, len(builtinShortcodes))
+	for name, src := range builtinShortcodes {
+		sources[name] = src
+	}
+
+	files, _ := filepath.Glob(filepath.Join(s.themeDir(), "shortcodes", "*.html"))
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read shortcode %s: %w", path, err)
+		}
+		sources[strings.TrimSuffix(filepath.Base(path), ".html")] = string(content)
+	}
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	set := &shortcodeSet{
+		templates: template.New("shortcodes").Funcs(layoutFuncs()),
+		paired:    make(map[string]bool, len(sources)),
+	}
+	hashed := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		if _, err := set.templates.New(name).Parse(sources[name]); err != nil {
+			return fmt.Errorf("failed to parse shortcode %s: %w", name, err)
+		}
+		set.paired[name] = strings.Contains(sources[name], ".Inner")
+		hashed = append(hashed, name, sources[name])
+	}
+	set.hash = hashStrings(hashed...)
+
+	s.shortcodes = set
+	return nil
+}
+
+// shortcodeLine matches a shortcode tag on a line of its own,
+// {{< name args >}} or {{< /name >}}
+var shortcodeLine = regexp.MustCompile(`^\{\{<\s*(/?)\s*([\w-]+)(.*?)\s*/?>\}\}$`)
+
+// shortcodeArg matches key=value, key="quoted value", "quoted" or bare
+// arguments of a shortcode
+var shortcodeArg = regexp.MustCompile(`([\w-]+)=("(?:[^"\\]|\\.)*"|\S+)|("(?:[^"\\]|\\.)*"|\S+)`)
+
+// parseShortcodeArgs splits the arguments of a shortcode tag
+func parseShortcodeArgs(raw string) ([]string, map[string]string) {
+	var args []string
+	params := make(map[string]string)
+	for _, m := range shortcodeArg.FindAllStringSubmatch(raw, -1) {
+		if m[1] != "" {
+			params[m[1]] = unquoteArg(m[2])
+		} else {
+			args = append(args, unquoteArg(m[3]))
+		}
+	}
+	return args, params
+}
+
+func unquoteArg(arg string) string {
+	if strings.HasPrefix(arg, `"`) {
+		if unquoted, err := strconv.Unquote(arg); err == nil {
+			return unquoted
+		}
+	}
+	return arg
+}
+
+// kindShortcode is the AST node kind of shortcodes
+var kindShortcode = ast.NewNodeKind("Shortcode")
+
+// shortcodeNode is a shortcode in a document. Paired shortcodes hold the
+// blocks between their opening and closing tag as children.
+type shortcodeNode struct {
+	ast.BaseBlock
+	Name   string
+	Args   []string
+	Params map[string]string
+	Links  *bundleLinks
+
+	after string // Template output following .Inner, written on exit
+}
+
+// Kind implements ast.Node
+func (n *shortcodeNode) Kind() ast.NodeKind {
+	return kindShortcode
+}
+
+// Dump implements ast.Node
+func (n *shortcodeNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Name": n.Name}, nil)
+}
+
+// shortcodeParser opens a shortcodeNode for a tag of a known shortcode.
+// Unknown names are left alone and render as the text written.
+type shortcodeParser struct {
+	set *shortcodeSet
+}
+
+// Trigger implements parser.BlockParser
+func (p *shortcodeParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+// Open implements parser.BlockParser
+func (p *shortcodeParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, _ := reader.PeekLine()
+	m := shortcodeLine.FindSubmatch(bytes.TrimSpace(line))
+	if m == nil || len(m[1]) > 0 || p.set.templates.Lookup(string(m[2])) == nil {
+		return nil, parser.NoChildren
+	}
+
+	name := string(m[2])
+	links, _ := pc.Get(bundleLinksKey).(*bundleLinks)
+	args, params := parseShortcodeArgs(string(m[3]))
+	node := &shortcodeNode{Name: name, Args: args, Params: params, Links: links}
+
+	reader.AdvanceToEOL()
+	if p.set.paired[name] {
+		return node, parser.HasChildren
+	}
+	return node, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser. Paired shortcodes take every line
+// up to their closing tag.
+func (p *shortcodeParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*shortcodeNode)
+	if !p.set.paired[n.Name] {
+		return parser.Close
+	}
+
+	line, _ := reader.PeekLine()
+	if m := shortcodeLine.FindSubmatch(bytes.TrimSpace(line)); m != nil && len(m[1]) > 0 && string(m[2]) == n.Name {
+		reader.AdvanceToEOL()
+		return parser.Close
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+// Close implements parser.BlockParser
+func (p *shortcodeParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser
+func (p *shortcodeParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser
+func (p *shortcodeParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// innerMarker stands in for .Inner while a paired shortcode is executed, so
+// its output can be split around the rendered children
+const innerMarker = "<!--bazel-shortcode-inner-->"
+
+// shortcodeRenderer executes the template of each shortcodeNode
+type shortcodeRenderer struct {
+	set *shortcodeSet
+}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r *shortcodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindShortcode, r.render)
+}
+
+func (r *shortcodeRenderer) render(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	node := n.(*shortcodeNode)
+	if !entering {
+		w.WriteString(node.after)
+		w.WriteByte('\n')
+		return ast.WalkContinue, nil
+	}
+
+	data := ShortcodeData{
+		Name:   node.Name,
+		Args:   node.Args,
+		Params: node.Params,
+		Inner:  template.HTML(innerMarker),
+		links:  node.Links,
+	}
+	var buf bytes.Buffer
+	if err := r.set.templates.ExecuteTemplate(&buf, node.Name, data); err != nil {
+		return ast.WalkStop, fmt.Errorf("failed to render shortcode %s: %w", node.Name, err)
+	}
+
+	before, after, found := strings.Cut(buf.String(), innerMarker)
+	w.WriteString(before)
+	node.after = after
+	if !found {
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
+
+// shortcodeExtension adds {{< name args >}} shortcodes to goldmark
+type shortcodeExtension struct {
+	set *shortcodeSet
+}
+
+// Extend implements goldmark.Extender
+func (e shortcodeExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(
+		util.Prioritized(&shortcodeParser{e.set}, 850),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&shortcodeRenderer{e.set}, 100),
+	))
+}
+
+const youtubeShortcode = `<div class="embed embed-video"><iframe src="https://www.youtube-nocookie.com/embed/{{ or (.Get "id") (.Get 0) }}" title="{{ or (.Get "title") "YouTube video" }}" loading="lazy" allow="accelerometer; clipboard-write; encrypted-media; gyroscope; picture-in-picture" allowfullscreen></iframe></div>`
+
+const gistShortcode = `<script src="https://gist.github.com/{{ or (.Get "user") (.Get 0) }}/{{ or (.Get "id") (.Get 1) }}.js{{ with or (.Get "file") (.Get 2) }}?file={{ . }}{{ end }}"></script>`
+
+const figureShortcode = `{{ $src := or (.Get "src") (.Get 0) }}{{ $caption := or (.Get "caption") (.Get 1) }}<figure{{ with .Get "class" }} class="{{ . }}"{{ end }}>
+{{- with .Get "link" }}<a href="{{ $.Link . }}">{{ end -}}
+<img src="{{ .Link $src }}" alt="{{ or (.Get "alt") $caption }}" loading="lazy" />
+{{- if .Get "link" }}</a>{{ end -}}
+{{ with $caption }}<figcaption>{{ . }}</figcaption>{{ end }}</figure>`
+
+const noteShortcode = `<aside class="callout callout-{{ or (.Get "type") (.Get 0) "note" }}">{{ with .Get "title" }}<p class="callout-title">{{ . }}</p>{{ end }}
+{{ .Inner }}</aside>`
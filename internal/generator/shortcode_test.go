@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseShortcodeArgs(t *testing.T) {
+	tests := []struct {
+		raw    string
+		args   []string
+		params map[string]string
+	}{
+		{``, nil, map[string]string{}},
+		{` abc123`, []string{"abc123"}, map[string]string{}},
+		{` user 42 "main file.go"`, []string{"user", "42", "main file.go"}, map[string]string{}},
+		{` src="a b.jpg" caption="Say \"hi\"" class=wide`, nil, map[string]string{"src": "a b.jpg", "caption": `Say "hi"`, "class": "wide"}},
+		{` warning title="Careful"`, []string{"warning"}, map[string]string{"title": "Careful"}},
+	}
+	for _, tt := range tests {
+		args, params := parseShortcodeArgs(tt.raw)
+		if !reflect.DeepEqual(args, tt.args) || !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("parseShortcodeArgs(%q) = %q, %q, want %q, %q", tt.raw, args, params, tt.args, tt.params)
+		}
+	}
+}
+
+const shortcodePost = `{{< youtube dQw4w9WgXcQ >}}
+
+{{< gist octocat 123abc main.go >}}
+
+{{< figure src="photo.jpg" caption="A photo" >}}
+
+{{< note warning title="Careful" >}}
+Some **bold** advice
+{{< /note >}}
+
+{{< unknown thing >}}
+
+{{< greeting name="Ada" >}}
+
+` + "```\n{{< youtube inside-code >}}\n```"
+
+func TestShortcodes(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/trip/index.md":                     markdownFile("title: Trip\ndate: 2024-01-02", shortcodePost),
+		"posts/trip/photo.jpg":                    "jpeg",
+		"themes/default/shortcodes/greeting.html": `<p class="greeting">Hello {{ .Get "name" }}</p>`,
+	})
+	buildTestSite(t)
+
+	assertContains(t, "posts/trip.html",
+		`<iframe src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ" title="YouTube video"`,
+		`<script src="https://gist.github.com/octocat/123abc.js?file=main.go"></script>`,
+		`<figure><img src="trip/photo.jpg" alt="A photo" loading="lazy" /><figcaption>A photo</figcaption></figure>`,
+		`<aside class="callout callout-warning"><p class="callout-title">Careful</p>`,
+		"<p>Some <strong>bold</strong> advice</p>\n</aside>",
+		"{{&lt; unknown thing &gt;}}",
+		`<p class="greeting">Hello Ada</p>`,
+		"{{&lt; youtube inside-code &gt;}}")
+
+	// Editing a theme shortcode re-renders the posts using it
+	writeSite(t, map[string]string{"themes/default/shortcodes/greeting.html": `<p class="greeting">Hi {{ .Get "name" }}</p>`})
+	buildTestSite(t)
+	assertContains(t, "posts/trip.html", `<p class="greeting">Hi Ada</p>`)
+}
+
+func TestShortcodeOverride(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/video.md":                         markdownFile("title: Video\ndate: 2024-01-02", "{{< youtube abc >}}"),
+		"themes/default/shortcodes/youtube.html": `<a class="video" href="https://youtu.be/{{ .Get 0 }}">Watch</a>`,
+	})
+	buildTestSite(t)
+
+	assertContains(t, "posts/video.html", `<a class="video" href="https://youtu.be/abc">Watch</a>`)
+	assertNotContains(t, "posts/video.html", "<iframe")
+}
+
+func TestInvalidShortcode(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/hello.md":                        markdownFile("title: Hello\ndate: 2024-01-02", "Hello"),
+		"themes/default/shortcodes/broken.html": `{{ .Get 0 `,
+	})
+	err := BuildSiteWithOptions(BuildOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to parse shortcode broken") {
+		t.Errorf("build error = %v, want a parse error for the broken shortcode", err)
+	}
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSite writes files, keyed by slash separated path, into the current
+// directory. An empty content deletes the file.
+func writeSite(t *testing.T, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.FromSlash(name)
+		if content == "" {
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestSite moves into a new site directory holding files
+func newTestSite(t *testing.T, files map[string]string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	writeSite(t, files)
+}
+
+func buildTestSite(t *testing.T) {
+	t.Helper()
+	buildTestSiteWith(t, BuildOptions{})
+}
+
+func buildTestSiteWith(t *testing.T, opts BuildOptions) {
+	t.Helper()
+	if err := BuildSiteWithOptions(opts); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+}
+
+// readOutput returns a file of the built site, relative to public/
+func readOutput(t *testing.T, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("public", filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// hasOutput reports whether the built site has a file, relative to public/
+func hasOutput(name string) bool {
+	_, err := os.Stat(filepath.Join("public", filepath.FromSlash(name)))
+	return err == nil
+}
+
+// assertContains fails unless the output file contains every string of want
+func assertContains(t *testing.T, name string, want ...string) {
+	t.Helper()
+	got := readOutput(t, name)
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("%s does not contain %q:\n%s", name, w, got)
+		}
+	}
+}
+
+// assertNotContains fails if the output file contains any string of unwanted
+func assertNotContains(t *testing.T, name string, unwanted ...string) {
+	t.Helper()
+	got := readOutput(t, name)
+	for _, u := range unwanted {
+		if strings.Contains(got, u) {
+			t.Errorf("%s contains %q:\n%s", name, u, got)
+		}
+	}
+}
+
+// markdownFile returns the source of a markdown post or page with YAML
+// frontmatter
+func markdownFile(frontmatter, body string) string {
+	return "---\n" + frontmatter + "\n---\n\n" + body + "\n"
+}
+
+// mustDate parses a YYYY-MM-DD date in UTC
+func mustDate(t *testing.T, date string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// sitemapEntry is a generated page listed in sitemap.xml
+type sitemapEntry struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// addToSitemap records a rendered page. Posts are dated by their post date,
+// pages by their file's modification time and lists by their newest post.
+// Drafts and future posts, which are only built for previews, are left out,
+// as are lists of nothing but such posts.
+func (s *Site) addToSitemap(data LayoutData) {
+	entry := sitemapEntry{Loc: data.Permalink}
+	switch {
+	case data.Post != nil && !s.public(data.Post):
+		return
+	case data.Page != nil && data.Page.Draft:
+		return
+	case data.Post != nil:
+		entry.LastMod = data.Post.Date
+		if !data.Post.Updated.IsZero() {
+			entry.LastMod = data.Post.Updated
+		}
+	case data.Page != nil:
+		entry.LastMod = data.Page.LastMod
+	default:
+		listed := 0
+		for i := range data.Posts {
+			post := &data.Posts[i]
+			if !s.public(post) {
+				continue
+			}
+			listed++
+			if post.Date.After(entry.LastMod) {
+				entry.LastMod = post.Date
+			}
+		}
+		if listed == 0 && len(data.Posts) > 0 && data.Section == nil {
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.sitemap = append(s.sitemap, entry)
+	s.mu.Unlock()
+}
+
+// public reports whether a post would be part of a build without --drafts
+// and --future
+func (s *Site) public(post *Post) bool {
+	return !post.Draft && !post.publishAt.After(s.now)
+}
+
+// generateSitemap writes sitemap.xml for every page rendered so far
+func (s *Site) generateSitemap() error {
+	sitemapTemplate := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+{{- range .}}
+	<url>
+		<loc>{{xml .Loc}}</loc>
+		{{- if not .LastMod.IsZero}}
+		<lastmod>{{.LastMod.Format "2006-01-02T15:04:05Z07:00"}}</lastmod>
+		{{- end}}
+	</url>
+{{- end}}
+</urlset>
+`
+
+	tmpl, err := texttemplate.New("sitemap").Funcs(feedFuncs).Parse(sitemapTemplate)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(s.sitemap, func(i, j int) bool {
+		return s.sitemap[i].Loc < s.sitemap[j].Loc
+	})
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s.sitemap); err != nil {
+		return err
+	}
+	return s.writeOutput("sitemap.xml", buf.Bytes())
+}
+
+// generateRobots writes robots.txt from the [robots] config, pointing
+// crawlers at the sitemap
+func (s *Site) generateRobots() error {
+	robots := s.Config.Robots
+
+	userAgent := robots.UserAgent
+	if userAgent == "" {
+		userAgent = "*"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "User-agent: %s\n", userAgent)
+	for _, path := range robots.Allow {
+		fmt.Fprintf(&b, "Allow: %s\n", path)
+	}
+	for _, path := range robots.Disallow {
+		fmt.Fprintf(&b, "Disallow: %s\n", path)
+	}
+	if len(robots.Disallow) == 0 {
+		b.WriteString("Disallow:\n") // Allow everything
+	}
+	fmt.Fprintf(&b, "\nSitemap: %s\n", s.absURL("sitemap.xml"))
+
+	return s.writeOutput("robots.txt", []byte(b.String()))
+}
@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+type sitemapURLs struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+func readSitemap(t *testing.T) map[string]string {
+	t.Helper()
+	var sitemap sitemapURLs
+	if err := xml.Unmarshal([]byte(readOutput(t, "sitemap.xml")), &sitemap); err != nil {
+		t.Fatalf("sitemap.xml is not valid XML: %v", err)
+	}
+	lastmod := make(map[string]string)
+	for _, url := range sitemap.URLs {
+		lastmod[url.Loc] = url.LastMod
+	}
+	return lastmod
+}
+
+func TestSitemap(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":     "title = \"Maps\"\nbase_url = \"https://example.com/blog/\"\n",
+		"posts/old.md":   markdownFile("title: Old\ndate: 2024-01-02\ntags: [go]", "Old post"),
+		"posts/new.md":   markdownFile("title: New & Shiny\ndate: 2024-05-06", "New post"),
+		"pages/about.md": markdownFile("title: About", "About me"),
+	})
+	buildTestSite(t)
+
+	lastmod := readSitemap(t)
+	for loc, want := range map[string]string{
+		"https://example.com/blog/":                 "2024-05-06",
+		"https://example.com/blog/posts/old.html":   "2024-01-02",
+		"https://example.com/blog/posts/new.html":   "2024-05-06",
+		"https://example.com/blog/tags/go/":         "2024-01-02",
+		"https://example.com/blog/archive/2024/01/": "2024-01-02",
+		"https://example.com/blog/pages/about.html": "", // Dated by its file
+	} {
+		got, ok := lastmod[loc]
+		if !ok {
+			t.Errorf("sitemap.xml is missing %s", loc)
+			continue
+		}
+		if got == "" || !strings.HasPrefix(got, want) {
+			t.Errorf("lastmod of %s = %q, want %s", loc, got, want)
+		}
+	}
+	for loc := range lastmod {
+		if strings.HasSuffix(loc, ".xml") || strings.HasSuffix(loc, ".json") {
+			t.Errorf("sitemap.xml lists feed %s", loc)
+		}
+	}
+}
+
+func TestSitemapPreview(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":      "title = \"Maps\"\nbase_url = \"https://example.com\"\n",
+		"posts/live.md":   markdownFile("title: Live\ndate: 2024-01-02\ntags: [go]", "Live"),
+		"posts/draft.md":  markdownFile("title: Draft\ndate: 2024-01-03\ndraft: true\ntags: [wip]", "Draft"),
+		"posts/future.md": markdownFile("title: Future\ndate: 2999-01-02\ntags: [go]", "Future"),
+		"pages/secret.md": markdownFile("title: Secret\ndraft: true", "Secret"),
+		"pages/about.md":  markdownFile("title: About", "About"),
+	})
+	buildTestSiteWith(t, BuildOptions{Drafts: true, Future: true})
+
+	if !hasOutput("posts/draft.html") || !hasOutput("posts/future.html") {
+		t.Fatal("the preview build left out drafts or future posts")
+	}
+	lastmod := readSitemap(t)
+	for _, loc := range []string{
+		"https://example.com/posts/draft.html",
+		"https://example.com/posts/future.html",
+		"https://example.com/pages/secret.html",
+		"https://example.com/tags/wip/",
+	} {
+		if _, ok := lastmod[loc]; ok {
+			t.Errorf("sitemap.xml lists %s", loc)
+		}
+	}
+	for _, loc := range []string{"https://example.com/posts/live.html", "https://example.com/pages/about.html"} {
+		if _, ok := lastmod[loc]; !ok {
+			t.Errorf("sitemap.xml is missing %s", loc)
+		}
+	}
+	if got := lastmod["https://example.com/tags/go/"]; !strings.HasPrefix(got, "2024-01-02") {
+		t.Errorf("lastmod of the go tag = %q, want the date of its public post", got)
+	}
+}
+
+func TestRobots(t *testing.T) {
+	tests := []struct {
+		name   string
+		robots string
+		want   string
+	}{
+		{
+			name: "defaults",
+			want: "User-agent: *\nDisallow:\n\nSitemap: https://example.com/sitemap.xml\n",
+		},
+		{
+			name:   "configured",
+			robots: "[robots]\nuser_agent = \"Googlebot\"\nallow = [\"/posts/\"]\ndisallow = [\"/drafts/\", \"/tmp/\"]\n",
+			want:   "User-agent: Googlebot\nAllow: /posts/\nDisallow: /drafts/\nDisallow: /tmp/\n\nSitemap: https://example.com/sitemap.xml\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestSite(t, map[string]string{
+				"bazel.toml":     "title = \"Robots\"\nbase_url = \"https://example.com\"\n" + tt.robots,
+				"posts/hello.md": markdownFile("title: Hello\ndate: 2024-01-02", "Hello"),
+			})
+			buildTestSite(t)
+
+			if got := readOutput(t, "robots.txt"); got != tt.want {
+				t.Errorf("robots.txt = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+const (
+	// moreMarker ends the summary of a post written by hand
+	moreMarker = "<!--more-->"
+
+	defaultSummaryWords = 50
+	wordsPerMinute      = 200
+)
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips the tags from rendered HTML and collapses whitespace
+func plainText(content string) string {
+	text := html.UnescapeString(htmlTag.ReplaceAllString(content, " "))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// summarize reduces rendered HTML to its first words of plain text
+func summarize(content string, words int) string {
+	fields := strings.Fields(plainText(content))
+	if len(fields) <= words {
+		return strings.Join(fields, " ")
+	}
+	return strings.Join(fields[:words], " ") + "…"
+}
+
+// readingTime estimates the minutes needed to read a number of words
+func readingTime(words int) int {
+	minutes := (words + wordsPerMinute - 1) / wordsPerMinute
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
+
+// postSummary picks the summary of a post: the description from its
+// frontmatter, the text before a <!--more--> marker, or its first words.
+func (s *Site) postSummary(description, markdown, content string) string {
+	if description != "" {
+		return description
+	}
+	if i := strings.Index(markdown, moreMarker); i >= 0 {
+		return plainText(s.markdownToHTML(markdown[:i], nil).HTML)
+	}
+
+	words := s.Config.SummaryLength
+	if words <= 0 {
+		words = defaultSummaryWords
+	}
+	return summarize(content, words)
+}
@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		html  string
+		words int
+		want  string
+	}{
+		{"<p>Hello <em>world</em></p>", 5, "Hello world"},
+		{"<p>one two three four</p>", 2, "one two…"},
+		{"<p>Fish &amp; chips</p>", 5, "Fish & chips"},
+	}
+	for _, tt := range tests {
+		if got := summarize(tt.html, tt.words); got != tt.want {
+			t.Errorf("summarize(%q, %d) = %q, want %q", tt.html, tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	for words, want := range map[int]int{0: 1, 150: 1, 200: 1, 201: 2, 1000: 5} {
+		if got := readingTime(words); got != want {
+			t.Errorf("readingTime(%d) = %d, want %d", words, got, want)
+		}
+	}
+}
+
+func TestPostSummaries(t *testing.T) {
+	long := strings.Repeat("word ", 450)
+	newTestSite(t, map[string]string{
+		"bazel.toml":         "title = \"Summaries\"\nsummary_length = 3\n",
+		"posts/described.md": markdownFile("title: Described\ndate: 2024-01-01\ndescription: Written by hand", "Body text"),
+		"posts/more.md":      markdownFile("title: More\ndate: 2024-01-02", "The *intro* part\n\n<!--more-->\n\nThe rest"),
+		"posts/auto.md":      markdownFile("title: Auto\ndate: 2024-01-03", "One two three four five"),
+		"posts/long.md":      markdownFile("title: Long\ndate: 2024-01-04", long),
+	})
+	buildTestSite(t)
+
+	assertContains(t, "index.html",
+		`<p class="post-summary">Written by hand`,
+		`<p class="post-summary">The intro part`,
+		`<p class="post-summary">One two three… <span class="reading-time">· 1 min read</span>`,
+		`<p class="post-summary">word word word… <span class="reading-time">· 3 min read</span>`)
+	assertContains(t, "posts/described.html", `<meta name="description" content="Written by hand">`)
+	assertContains(t, "posts/long.html", "· 3 min read")
+}
@@ -0,0 +1,209 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Taxonomy groups posts by the values of a frontmatter list such as tags
+type Taxonomy struct {
+	Name     string // Plural name used in URLs, e.g. "tags"
+	Title    string // e.g. "Tags"
+	Singular string // e.g. "tag"
+	URL      string // Overview page, e.g. "tags/"
+	Terms    []*Term
+}
+
+// Term is a single value of a taxonomy together with the posts using it
+type Term struct {
+	Name  string
+	Slug  string
+	URL   string // e.g. "tags/go/"
+	Posts []Post
+}
+
+// taxonomies lists the frontmatter lists posts are grouped by
+var taxonomies = []struct {
+	name, title, singular string
+	terms                 func(*Post) []string
+}{
+	{"tags", "Tags", "tag", func(p *Post) []string { return p.Tags }},
+	{"categories", "Categories", "category", func(p *Post) []string { return p.Categories }},
+	{"series", "Series", "series", func(p *Post) []string { return seriesTerms(p) }},
+}
+
+// StringList is a frontmatter list that may also be written as a single
+// string, so both `tags: go` and `tags: [go, web]` are accepted.
+type StringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (l *StringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*l = splitList(single)
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// UnmarshalTOML implements toml.Unmarshaler
+func (l *StringList) UnmarshalTOML(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		*l = splitList(v)
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected a list of strings, got %T", item)
+			}
+			list = append(list, str)
+		}
+		*l = list
+	default:
+		return fmt.Errorf("expected a string or a list of strings, got %T", value)
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = splitList(single)
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// splitList splits a comma separated string into trimmed, non-empty values
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// slugify turns a term into a lowercase, URL safe path segment
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// buildTaxonomies groups the loaded posts by each taxonomy. Terms that only
+// differ in case or punctuation share a slug and are merged.
+func (s *Site) buildTaxonomies() {
+	s.Taxonomies = make(map[string]*Taxonomy, len(taxonomies))
+
+	for _, def := range taxonomies {
+		tax := &Taxonomy{
+			Name:     def.name,
+			Title:    def.title,
+			Singular: def.singular,
+			URL:      def.name + "/",
+		}
+		bySlug := make(map[string]*Term)
+
+		// Posts are already sorted newest first, which each term keeps
+		for i := range s.Posts {
+			for _, name := range def.terms(&s.Posts[i]) {
+				slug := slugify(name)
+				if slug == "" {
+					continue
+				}
+				term, ok := bySlug[slug]
+				if !ok {
+					term = &Term{
+						Name: name,
+						Slug: slug,
+						URL:  path.Join(def.name, slug) + "/",
+					}
+					bySlug[slug] = term
+					tax.Terms = append(tax.Terms, term)
+				}
+				term.Posts = append(term.Posts, s.Posts[i])
+			}
+		}
+
+		sort.Slice(tax.Terms, func(i, j int) bool {
+			return tax.Terms[i].Slug < tax.Terms[j].Slug
+		})
+		s.Taxonomies[def.name] = tax
+	}
+}
+
+// generateTaxonomies writes an overview page for each taxonomy and a list
+// page and RSS feed for every term.
+func (s *Site) generateTaxonomies() error {
+	for _, def := range taxonomies {
+		tax := s.Taxonomies[def.name]
+		if len(tax.Terms) == 0 {
+			continue
+		}
+
+		err := s.renderLayout("taxonomy", tax.URL+"index.html", LayoutData{
+			Title:       tax.Title,
+			Description: fmt.Sprintf("All %s - %s", tax.Name, s.Config.Description),
+			URL:         tax.URL,
+			Permalink:   s.absURL(tax.URL),
+			Taxonomy:    tax,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = parallel(len(tax.Terms), func(i int) error {
+			term := tax.Terms[i]
+			err := s.renderLayout("term", term.URL+"index.html", LayoutData{
+				Title:       term.Name,
+				Description: fmt.Sprintf("Posts in %s %s - %s", tax.Singular, term.Name, s.Config.Description),
+				URL:         term.URL,
+				Permalink:   s.absURL(term.URL),
+				Posts:       term.Posts,
+				Taxonomy:    tax,
+				Term:        term,
+			})
+			if err != nil {
+				return err
+			}
+
+			title := fmt.Sprintf("%s - %s", s.Config.Title, term.Name)
+			return s.writeRSS(term.URL+"feed.xml", title, s.absURL(term.URL), term.Posts)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
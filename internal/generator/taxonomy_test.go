@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adrg/frontmatter"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Go":                    "go",
+		"Web Development":       "web-development",
+		"  C++ & Rust!  ":       "c-rust",
+		"Ünïcode Wörds":         "ünïcode-wörds",
+		"already-a-slug":        "already-a-slug",
+		"---":                   "",
+		"2024: A Retrospective": "2024-a-retrospective",
+	}
+	for name, want := range tests {
+		if got := slugify(name); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestTaxonomyPages(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"bazel.toml":      "base_url = \"https://example.com\"\n",
+		"posts/first.md":  markdownFile("title: First\ndate: 2024-01-01\ntags: [Go, Web]\ncategories: Tutorials", "first"),
+		"posts/second.md": markdownFile("title: Second\ndate: 2024-02-01\ntags: go, testing", "second"),
+		"posts/plain.md":  markdownFile("title: Plain\ndate: 2024-03-01", "no terms"),
+	})
+	buildTestSite(t)
+
+	// Terms differing only in case share a page, listing posts newest first
+	assertContains(t, "tags/go/index.html", "Second", "First")
+	if page := readOutput(t, "tags/go/index.html"); strings.Index(page, "Second") > strings.Index(page, "First") {
+		t.Error("tags/go/ does not list the newest post first")
+	}
+	assertNotContains(t, "tags/go/index.html", "Plain")
+	assertContains(t, "tags/web/index.html", "First")
+	assertNotContains(t, "tags/web/index.html", "Second")
+	assertContains(t, "tags/testing/index.html", "Second")
+	assertContains(t, "categories/tutorials/index.html", "First")
+
+	// Overview pages link every term
+	assertContains(t, "tags/index.html", "tags/go/", "tags/testing/", "tags/web/")
+	assertContains(t, "categories/index.html", "categories/tutorials/")
+
+	// Every term has its own feed
+	assertContains(t, "tags/go/feed.xml", "<rss", "https://example.com/posts/first.html", "https://example.com/posts/second.html")
+	assertNotContains(t, "tags/web/feed.xml", "second.html")
+}
+
+func TestTaxonomyPagesWithoutTerms(t *testing.T) {
+	newTestSite(t, map[string]string{
+		"posts/plain.md": markdownFile("title: Plain\ndate: 2024-03-01", "no terms"),
+	})
+	buildTestSite(t)
+
+	for _, file := range []string{"tags/index.html", "categories/index.html"} {
+		if hasOutput(file) {
+			t.Errorf("%s was generated without any terms", file)
+		}
+	}
+}
+
+func TestStringList(t *testing.T) {
+	tests := []struct {
+		frontmatter string
+		want        []string
+	}{
+		{"tags: go", []string{"go"}},
+		{"tags: go, web ,  testing", []string{"go", "web", "testing"}},
+		{"tags: [go, web]", []string{"go", "web"}},
+		{"tags:\n  - go\n  - web", []string{"go", "web"}},
+		{"tags: ''", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.frontmatter, func(t *testing.T) {
+			var matter PostMatter
+			content := markdownFile(tt.frontmatter, "body")
+			if _, err := frontmatter.Parse(strings.NewReader(content), &matter); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(matter.Tags, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("tags = %q, want %q", matter.Tags, tt.want)
+			}
+		})
+	}
+}
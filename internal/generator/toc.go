@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// tocHeading is a heading collected for the table of contents
+type tocHeading struct {
+	Level int
+	ID    string
+	Text  string
+}
+
+// tocKey carries the headings of a document out of a conversion
+var tocKey = parser.NewContextKey()
+
+// headingTransformer collects the headings of a document for its table of
+// contents and adds a permalink anchor to each heading with an ID
+type headingTransformer struct{}
+
+// Transform implements parser.ASTTransformer
+func (headingTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var headings []tocHeading
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		id, ok := heading.AttributeString("id")
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+		idStr := string(id.([]byte))
+
+		headings = append(headings, tocHeading{
+			Level: heading.Level,
+			ID:    idStr,
+			Text:  nodeText(heading, source),
+		})
+
+		// The anchor has no text of its own, the stylesheet draws it, so
+		// summaries and feed readers don't pick up stray symbols
+		anchor := ast.NewLink()
+		anchor.Destination = []byte("#" + idStr)
+		anchor.Title = []byte("Permalink to this section")
+		anchor.SetAttributeString("class", []byte("heading-anchor"))
+		heading.AppendChild(heading, anchor)
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	pc.Set(tocKey, headings)
+}
+
+// nodeText returns the plain text of an inline node tree
+func nodeText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := child.(type) {
+		case *ast.Text:
+			b.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// tocHTML renders headings as nested lists following the heading levels.
+// Skipped levels, such as an h4 directly below an h2, nest one step only.
+func tocHTML(headings []tocHeading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<nav class="toc">`)
+
+	var levels []int // Levels of the open lists
+	for _, h := range headings {
+		for len(levels) > 1 && h.Level <= levels[len(levels)-2] {
+			b.WriteString("</li></ul>")
+			levels = levels[:len(levels)-1]
+		}
+		if len(levels) == 0 || h.Level > levels[len(levels)-1] {
+			b.WriteString("<ul>")
+			levels = append(levels, h.Level)
+		} else {
+			b.WriteString("</li>")
+		}
+		b.WriteString(`<li><a href="#` + html.EscapeString(h.ID) + `">` + html.EscapeString(h.Text) + `</a>`)
+	}
+	for range levels {
+		b.WriteString("</li></ul>")
+	}
+
+	b.WriteString("</nav>")
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/yourusername/bazel_blog/internal/config"
+)
+
+// newTestRenderer returns a site that converts markdown with the default config
+func newTestRenderer(t *testing.T) *Site {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	cfg := config.DefaultConfig
+	s, err := newSite(&cfg, BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+var headingID = regexp.MustCompile(`<h\d id="([^"]*)"`)
+
+func TestHeadingIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     []string
+	}{
+		{"unique", "# One\n\n## Two\n\n### Three", []string{"one", "two", "three"}},
+		{"repeated", "## Intro\n\n## Intro\n\n### Intro", []string{"intro", "intro-1", "intro-2"}},
+		{"punctuation", "## Hello, World!\n\n## Hello World", []string{"hello-world", "hello-world-1"}},
+		{"repeated across levels", "# Setup\n\n## Steps\n\n# Usage\n\n## Steps", []string{"setup", "steps", "usage", "steps-1"}},
+	}
+
+	s := newTestRenderer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := s.markdownToHTML(tt.markdown, nil).HTML
+			var got []string
+			for _, m := range headingID.FindAllStringSubmatch(html, -1) {
+				got = append(got, m[1])
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("heading IDs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// IDs are numbered per document, not across the documents of a build that
+// share one markdown engine
+func TestHeadingIDsPerDocument(t *testing.T) {
+	s := newTestRenderer(t)
+	for _, markdown := range []string{"## Notes\n\nfirst", "## Notes\n\nsecond"} {
+		rendered := s.markdownToHTML(markdown, nil)
+		if m := headingID.FindStringSubmatch(rendered.HTML); m == nil || m[1] != "notes" {
+			t.Errorf("heading ID in %q = %v, want notes", markdown, m)
+		}
+	}
+}
+
+func TestTocHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		headings []tocHeading
+		want     string
+	}{
+		{"empty", nil, ""},
+		{
+			"flat",
+			[]tocHeading{{2, "a", "A"}, {2, "b", "B"}},
+			`<nav class="toc"><ul><li><a href="#a">A</a></li><li><a href="#b">B</a></li></ul></nav>`,
+		},
+		{
+			"nested",
+			[]tocHeading{{2, "a", "A"}, {3, "a1", "A1"}, {2, "b", "B"}},
+			`<nav class="toc"><ul><li><a href="#a">A</a><ul><li><a href="#a1">A1</a></li></ul></li><li><a href="#b">B</a></li></ul></nav>`,
+		},
+		{
+			"skipped level",
+			[]tocHeading{{2, "a", "A"}, {4, "a1", "A1"}, {3, "a2", "A2"}},
+			`<nav class="toc"><ul><li><a href="#a">A</a><ul><li><a href="#a1">A1</a></li><li><a href="#a2">A2</a></li></ul></li></ul></nav>`,
+		},
+		{
+			"back up two levels",
+			[]tocHeading{{1, "a", "A"}, {2, "b", "B"}, {3, "c", "C"}, {1, "d", "D"}},
+			`<nav class="toc"><ul><li><a href="#a">A</a><ul><li><a href="#b">B</a><ul><li><a href="#c">C</a></li></ul></li></ul></li><li><a href="#d">D</a></li></ul></nav>`,
+		},
+		{
+			"escaped",
+			[]tocHeading{{2, "x", "<b> & co"}},
+			`<nav class="toc"><ul><li><a href="#x">&lt;b&gt; &amp; co</a></li></ul></nav>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tocHTML(tt.headings); got != tt.want {
+				t.Errorf("tocHTML() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableOfContents(t *testing.T) {
+	body := "## Install\n\n### Linux\n\n## Usage"
+	newTestSite(t, map[string]string{
+		"bazel.toml":     "title = \"TOC\"\ntoc = true\n",
+		"posts/guide.md": markdownFile("title: Guide\ndate: 2024-01-02", body),
+		"posts/short.md": markdownFile("title: Short\ndate: 2024-01-03\ntoc: false", body),
+	})
+	buildTestSite(t)
+
+	assertContains(t, "posts/guide.html",
+		`<nav class="toc"><ul><li><a href="#install">Install</a><ul><li><a href="#linux">Linux</a>`,
+		`<a href="#usage" title="Permalink to this section" class="heading-anchor"></a>`)
+	assertNotContains(t, "posts/short.html", `<nav class="toc">`)
+	assertContains(t, "posts/short.html", `class="heading-anchor"`)
+}
@@ -79,37 +79,34 @@ func getThemeColors(theme string) (bgColor, textColor, accentColor string) {
 // getThemeStyle applies theme colors to text
 func getThemeStyle(theme, text string) string {
 	bgColor, textColor, _ := getThemeColors(theme)
-	reset := "\033[0m"
-	return bgColor + textColor + text + reset
+	return colorize(bgColor+textColor, text)
 }
 
 // getThemeAccentStyle applies theme accent color to text
 func getThemeAccentStyle(theme, text string) string {
 	_, _, accentColor := getThemeColors(theme)
-	reset := "\033[0m"
-	return accentColor + text + reset
+	return colorize(accentColor, text)
 }
 
 // getThemePreview returns a colorized preview for the theme
 func getThemePreview(theme string) string {
 	bgColor, textColor, accentColor := getThemeColors(theme)
-	reset := "\033[0m"
 
 	switch theme {
 	case "catppuccin-latte":
-		return bgColor + textColor + "☀️ Light & Warm" + reset + " " + accentColor + "Purple accents" + reset
+		return colorize(bgColor+textColor, "☀️ Light & Warm") + " " + colorize(accentColor, "Purple accents")
 	case "catppuccin-frappe":
-		return bgColor + textColor + "🌙 Medium Dark" + reset + " " + accentColor + "Soft purple" + reset
+		return colorize(bgColor+textColor, "🌙 Medium Dark") + " " + colorize(accentColor, "Soft purple")
 	case "catppuccin-macchiato":
-		return bgColor + textColor + "🌃 Dark & Cozy" + reset + " " + accentColor + "Vibrant purple" + reset
+		return colorize(bgColor+textColor, "🌃 Dark & Cozy") + " " + colorize(accentColor, "Vibrant purple")
 	case "catppuccin-mocha":
-		return bgColor + textColor + "🌌 Darkest" + reset + " " + accentColor + "Beautiful purple" + reset
+		return colorize(bgColor+textColor, "🌌 Darkest") + " " + colorize(accentColor, "Beautiful purple")
 	case "dracula":
-		return bgColor + textColor + "🧛 Classic Dark" + reset + " " + accentColor + "Purple magic" + reset
+		return colorize(bgColor+textColor, "🧛 Classic Dark") + " " + colorize(accentColor, "Purple magic")
 	case "nord":
-		return bgColor + textColor + "🏔️ Arctic Blue" + reset + " " + accentColor + "Cool blues" + reset
+		return colorize(bgColor+textColor, "🏔️ Arctic Blue") + " " + colorize(accentColor, "Cool blues")
 	case "tokyo-night":
-		return bgColor + textColor + "🏙️ Neon Night" + reset + " " + accentColor + "Electric blue" + reset
+		return colorize(bgColor+textColor, "🏙️ Neon Night") + " " + colorize(accentColor, "Electric blue")
 	default:
 		return "Preview not available"
 	}
@@ -136,17 +133,24 @@ const (
 	ColorBrightWhite   = "\033[97m"
 )
 
+// NoColor turns off colors and text styles, for the --no-color flag and the
+// NO_COLOR environment variable
+var NoColor = os.Getenv("NO_COLOR") != ""
+
 // Style helper functions
 func colorize(color, text string) string {
+	if NoColor {
+		return text
+	}
 	return color + text + ColorReset
 }
 
 func bold(text string) string {
-	return ColorBold + text + ColorReset
+	return colorize(ColorBold, text)
 }
 
 func dim(text string) string {
-	return ColorDim + text + ColorReset
+	return colorize(ColorDim, text)
 }
 
 func formatTitle(text string) string {
@@ -214,6 +218,9 @@ const (
 	PageDeleteMenu
 	PostDeleteConfirmMenu
 	PageDeleteConfirmMenu
+	PostDraftMenu
+	PageDraftMenu
+	PageOrganizeMenu
 )
 
 type model struct {
@@ -228,9 +235,10 @@ type model struct {
 	editingDomain      string
 	editingDescription string
 	message            string
-	previewFont        string // Font being previewed in font menu
-	previewTheme       string // Theme being previewed in theme menu
-	selectedItem       string // Track selected item for deletion
+	previewFont        string               // Font being previewed in font menu
+	previewTheme       string               // Theme being previewed in theme menu
+	selectedItem       string               // Track selected item for deletion
+	navEntries         []generator.NavEntry // Pages being reordered, in step with choices
 }
 
 func RunPostMenu() {
@@ -285,7 +293,7 @@ func RunPageMenu() {
 }
 
 func RunThemeMenu() {
-	cfg, err := config.LoadConfig()
+	cfg, err := config.LoadBaseConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		return
@@ -313,7 +321,7 @@ func RunThemeMenu() {
 }
 
 func RunFontMenu() {
-	cfg, err := config.LoadConfig()
+	cfg, err := config.LoadBaseConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		return
@@ -341,7 +349,7 @@ func RunFontMenu() {
 }
 
 func RunConfigMenu() {
-	cfg, err := config.LoadConfig()
+	cfg, err := config.LoadBaseConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		return
@@ -406,6 +414,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updatePostDeleteConfirmMenu(msg)
 		case PageDeleteConfirmMenu:
 			return m.updatePageDeleteConfirmMenu(msg)
+		case PostDraftMenu:
+			return m.updatePostDraftMenu(msg)
+		case PageDraftMenu:
+			return m.updatePageDraftMenu(msg)
+		case PageOrganizeMenu:
+			return m.updatePageOrganizeMenu(msg)
 		}
 	}
 
@@ -457,8 +471,17 @@ func (m model) updateMainMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					m.state = PostDeleteMenu
 				}
 			case 3: // Draft Posts
-				m.message = "Draft Posts are not implemented yet. Coming soon! Press any key to return."
-				return m, nil
+				// Load draft posts and switch to draft menu
+				drafts, err := generator.ListDraftPosts()
+				if err != nil {
+					m.message = fmt.Sprintf("Error loading drafts: %v", err)
+				} else if len(drafts) == 0 {
+					m.message = "No draft posts found. Add 'draft: true' to a post's frontmatter or move it to posts/drafts/"
+				} else {
+					m.choices = drafts
+					m.cursor = 0
+					m.state = PostDraftMenu
+				}
 			case 4: // Done
 				return m, tea.Quit
 			}
@@ -493,11 +516,33 @@ func (m model) updateMainMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					m.state = PageDeleteMenu
 				}
 			case 3: // Draft Pages
-				m.message = "Draft Pages are not implemented yet. Coming soon! Press any key to return."
-				return m, nil
+				// Load draft pages and switch to draft menu
+				drafts, err := generator.ListDraftPages()
+				if err != nil {
+					m.message = fmt.Sprintf("Error loading drafts: %v", err)
+				} else if len(drafts) == 0 {
+					m.message = "No draft pages found. Add 'draft: true' to a page's frontmatter or move it to pages/drafts/"
+				} else {
+					m.choices = drafts
+					m.cursor = 0
+					m.state = PageDraftMenu
+				}
 			case 4: // Organize Pages
-				m.message = "Organize Pages feature is not available yet. Coming soon! Press any key to return."
-				return m, nil
+				// Load the navigation order and switch to the organize menu
+				entries, err := generator.ListNavEntries()
+				if err != nil {
+					m.message = fmt.Sprintf("Error loading pages: %v", err)
+				} else if len(entries) == 0 {
+					m.message = "No markdown pages found to organize"
+				} else {
+					m.navEntries = entries
+					m.choices = make([]string, len(entries))
+					for i, entry := range entries {
+						m.choices[i] = entry.Title
+					}
+					m.cursor = 0
+					m.state = PageOrganizeMenu
+				}
 			case 5: // Done
 				return m, tea.Quit
 			}
@@ -1133,7 +1178,7 @@ func (m model) View() string {
 				}
 				s.WriteString(fmt.Sprintf("%s %s\n", cursor, post))
 			}
-			s.WriteString("\n(Press Enter to edit, 'r' to retry, 'l' to refresh list, Esc to go back, Ctrl+C to quit)")
+			s.WriteString("\n(Press Enter to edit, 'u' to unpublish, 'r' to retry, 'l' to refresh list, Esc to go back, Ctrl+C to quit)")
 		}
 
 	case PageEditMenu:
@@ -1145,7 +1190,7 @@ func (m model) View() string {
 			}
 			s.WriteString(fmt.Sprintf("%s %s\n", cursor, page))
 		}
-		s.WriteString("\n(Press Enter to edit, Esc to go back, Ctrl+C to quit)")
+		s.WriteString("\n(Press Enter to edit, 'u' to unpublish, Esc to go back, Ctrl+C to quit)")
 
 	case PostDeleteMenu:
 		s.WriteString("Select a post to delete:\n\n")
@@ -1169,6 +1214,39 @@ func (m model) View() string {
 		}
 		s.WriteString("\n(Press Enter to delete, Esc to go back, Ctrl+C to quit)")
 
+	case PostDraftMenu:
+		s.WriteString("Draft posts (not included in 'bazel build'):\n\n")
+		for i, post := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+			}
+			s.WriteString(fmt.Sprintf("%s %s\n", cursor, post))
+		}
+		s.WriteString("\n(Press Enter to publish, 'e' to edit, Esc to go back, Ctrl+C to quit)")
+
+	case PageDraftMenu:
+		s.WriteString("Draft pages (not included in 'bazel build'):\n\n")
+		for i, page := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+			}
+			s.WriteString(fmt.Sprintf("%s %s\n", cursor, page))
+		}
+		s.WriteString("\n(Press Enter to publish, 'e' to edit, Esc to go back, Ctrl+C to quit)")
+
+	case PageOrganizeMenu:
+		s.WriteString("Navigation order:\n\n")
+		for i, page := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+			}
+			s.WriteString(fmt.Sprintf("%s %d. %s\n", cursor, i+1, page))
+		}
+		s.WriteString("\n(Shift+↑/↓ or K/J to move the selected page, Enter to save, Esc to cancel, Ctrl+C to quit)")
+
 	case PostDeleteConfirmMenu:
 		if m.selectedItem != "" {
 			s.WriteString(fmt.Sprintf("⚠️  Delete Post: %s\n\n", m.selectedItem))
@@ -1311,14 +1389,16 @@ func (m model) updatePostEditMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			// Check if post file exists with comprehensive file validation
-			postPath := fmt.Sprintf("posts/%s.md", selectedPost)
-			fileInfo, err := os.Stat(postPath)
-			if os.IsNotExist(err) {
+			// Check if post file exists with comprehensive file validation,
+			// looking into drafts and page bundles like EditPost does
+			postPath, err := generator.PostFile(selectedPost)
+			if err != nil {
 				m.message = formatError(fmt.Sprintf("Post file not found: %s", selectedPost))
 				m.message += "\n" + formatInstruction("Press 'r' to refresh post list, 'esc' to go back")
 				return m, nil
-			} else if err != nil {
+			}
+			fileInfo, err := os.Stat(postPath)
+			if err != nil {
 				m.message = formatError(fmt.Sprintf("Error accessing post file: %v", err))
 				m.message += "\n" + formatInstruction("Press 'r' to retry, 'esc' to go back")
 				return m, nil
@@ -1386,6 +1466,19 @@ func (m model) updatePostEditMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.cursor = 1
 			}
 		}
+	case "u":
+		// Turn the selected post back into a draft
+		if m.cursor < len(m.choices) && len(m.choices) > 0 {
+			selectedPost := m.choices[m.cursor]
+			if err := generator.UnpublishPost(selectedPost); err != nil {
+				m.message = formatError(err.Error())
+			} else {
+				m.message = formatSuccess(fmt.Sprintf("Moved %s to drafts", selectedPost))
+				m.state = MainMenu
+				m.choices = []string{"New Post", "Edit Post", "Delete Post", "Draft Posts", "Done"}
+				m.cursor = 3 // Focus on "Draft Posts"
+			}
+		}
 	case "l":
 		// Refresh post list functionality
 		m.message = "🔄 Refreshing post list..."
@@ -1417,7 +1510,7 @@ func (m model) updatePageEditMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc":
 		// Go back to main page menu
 		m.state = MainMenu
-		m.choices = []string{"New Page", "Edit Page", "Draft Pages", "Organize Pages", "Done"}
+		m.choices = []string{"New Page", "Edit Page", "Delete Page", "Draft Pages", "Organize Pages", "Done"}
 		m.cursor = 0
 	case "up", "k":
 		if m.cursor > 0 {
@@ -1438,9 +1531,110 @@ func (m model) updatePageEditMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			// Return to main page menu after editing
 			m.state = MainMenu
-			m.choices = []string{"New Page", "Edit Page", "Draft Pages", "Organize Pages", "Done"}
+			m.choices = []string{"New Page", "Edit Page", "Delete Page", "Draft Pages", "Organize Pages", "Done"}
 			m.cursor = 0
 		}
+	case "u":
+		// Turn the selected page back into a draft
+		if m.cursor < len(m.choices) {
+			selectedPage := m.choices[m.cursor]
+			if err := generator.UnpublishPage(selectedPage); err != nil {
+				m.message = fmt.Sprintf("Error: %v", err)
+			} else {
+				m.message = fmt.Sprintf("Moved %s to drafts", selectedPage)
+				m.state = MainMenu
+				m.choices = []string{"New Page", "Edit Page", "Delete Page", "Draft Pages", "Organize Pages", "Done"}
+				m.cursor = 3 // Focus on "Draft Pages"
+			}
+		}
+	}
+	return m, nil
+}
+
+// updatePostDraftMenu handles publishing and editing draft posts
+func (m model) updatePostDraftMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		// Go back to main post menu
+		m.state = MainMenu
+		m.choices = []string{"New Post", "Edit Post", "Delete Post", "Draft Posts", "Done"}
+		m.cursor = 3
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.choices)-1 {
+			m.cursor++
+		}
+	case "e":
+		if m.cursor < len(m.choices) {
+			selectedPost := m.choices[m.cursor]
+			if err := generator.EditPost(selectedPost); err != nil {
+				m.message = fmt.Sprintf("Error opening draft for editing: %v", err)
+			} else {
+				m.message = fmt.Sprintf("Opened %s in editor", selectedPost)
+			}
+		}
+	case "enter":
+		if m.cursor < len(m.choices) {
+			selectedPost := m.choices[m.cursor]
+			if err := generator.PublishPost(selectedPost); err != nil {
+				m.message = fmt.Sprintf("Error: %v", err)
+			} else {
+				m.message = fmt.Sprintf("Published post: %s", selectedPost)
+			}
+			// Return to main post menu after publishing
+			m.state = MainMenu
+			m.choices = []string{"New Post", "Edit Post", "Delete Post", "Draft Posts", "Done"}
+			m.cursor = 3
+		}
+	}
+	return m, nil
+}
+
+// updatePageDraftMenu handles publishing and editing draft pages
+func (m model) updatePageDraftMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		// Go back to main page menu
+		m.state = MainMenu
+		m.choices = []string{"New Page", "Edit Page", "Delete Page", "Draft Pages", "Organize Pages", "Done"}
+		m.cursor = 3
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.choices)-1 {
+			m.cursor++
+		}
+	case "e":
+		if m.cursor < len(m.choices) {
+			selectedPage := m.choices[m.cursor]
+			if err := generator.EditPage(selectedPage); err != nil {
+				m.message = fmt.Sprintf("Error opening draft for editing: %v", err)
+			} else {
+				m.message = fmt.Sprintf("Opened %s in editor", selectedPage)
+			}
+		}
+	case "enter":
+		if m.cursor < len(m.choices) {
+			selectedPage := m.choices[m.cursor]
+			if err := generator.PublishPage(selectedPage); err != nil {
+				m.message = fmt.Sprintf("Error: %v", err)
+			} else {
+				m.message = fmt.Sprintf("Published page: %s", selectedPage)
+			}
+			// Return to main page menu after publishing
+			m.state = MainMenu
+			m.choices = []string{"New Page", "Edit Page", "Delete Page", "Draft Pages", "Organize Pages", "Done"}
+			m.cursor = 3
+		}
 	}
 	return m, nil
 }
@@ -1576,3 +1770,54 @@ func (m model) updatePageDeleteConfirmMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 	}
 	return m, nil
 }
+
+// updatePageOrganizeMenu moves pages within the navigation and saves the
+// new order as page weights
+func (m model) updatePageOrganizeMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		// Go back to main page menu without saving
+		m.state = MainMenu
+		m.choices = []string{"New Page", "Edit Page", "Delete Page", "Draft Pages", "Organize Pages", "Done"}
+		m.cursor = 4
+		m.navEntries = nil
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.choices)-1 {
+			m.cursor++
+		}
+	case "shift+up", "K":
+		if m.cursor > 0 {
+			m.swapNavEntries(m.cursor, m.cursor-1)
+			m.cursor--
+		}
+	case "shift+down", "J":
+		if m.cursor < len(m.choices)-1 {
+			m.swapNavEntries(m.cursor, m.cursor+1)
+			m.cursor++
+		}
+	case "enter":
+		if err := generator.SaveNavOrder(m.navEntries); err != nil {
+			m.message = fmt.Sprintf("Error saving page order: %v", err)
+		} else {
+			m.message = "Page order saved. Run 'bazel build' to update the navigation"
+		}
+		// Return to main page menu after saving
+		m.state = MainMenu
+		m.choices = []string{"New Page", "Edit Page", "Delete Page", "Draft Pages", "Organize Pages", "Done"}
+		m.cursor = 4
+		m.navEntries = nil
+	}
+	return m, nil
+}
+
+// swapNavEntries swaps two pages in the organize menu
+func (m model) swapNavEntries(i, j int) {
+	m.choices[i], m.choices[j] = m.choices[j], m.choices[i]
+	m.navEntries[i], m.navEntries[j] = m.navEntries[j], m.navEntries[i]
+}
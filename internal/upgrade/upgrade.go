@@ -416,7 +416,7 @@ func isPageFile(filename string) bool {
 
 // convertJSONConfigToTOML converts a JSON format bazel.toml to proper TOML format
 func convertJSONConfigToTOML() error {
-	configPath := "bazel.toml"
+	configPath := config.Path
 
 	// Read the current config file
 	data, err := os.ReadFile(configPath)
@@ -492,7 +492,7 @@ func convertJSONConfigToTOML() error {
 // Utility functions
 
 func isInBazelSite() bool {
-	_, err := os.Stat("bazel.toml")
+	_, err := os.Stat(config.Path)
 	return err == nil
 }
 
@@ -531,12 +531,12 @@ func CheckSiteVersion() (string, error) {
 
 // BackupConfig creates a backup of the current configuration
 func BackupConfig() error {
-	configPath := "bazel.toml"
+	configPath := config.Path
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return nil // No config to backup
 	}
 
-	backupPath := fmt.Sprintf("bazel.toml.backup.%s", time.Now().Format("20060102-150405"))
+	backupPath := fmt.Sprintf("%s.backup.%s", configPath, time.Now().Format("20060102-150405"))
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -554,7 +554,7 @@ func BackupConfig() error {
 
 // UpgradeConfig updates configuration file with new features
 func UpgradeConfig() error {
-	cfg, err := config.LoadConfig()
+	cfg, err := config.LoadBaseConfig()
 	if err != nil {
 		return err
 	}